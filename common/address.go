@@ -1,5 +1,10 @@
 package common
 
+import "fmt"
+
+// AddressLength is the length in bytes of the Address of a key
+const AddressLength = 20
+
 // Address represents the address for an Account
 // Placeholder for [20]byte type Addresses.
 type Address string
@@ -18,3 +23,24 @@ func NullAddress() Address {
 func MinerAddress() Address {
 	return "manish"
 }
+
+// StakingAddress returns the Address that holds the tokens locked by stakers
+func StakingAddress() Address {
+	return "staking"
+}
+
+// HexToAddress returns the Address of a key for the given 0x prefixed hex string.
+// Returns an error if it is not the hex encoding of AddressLength bytes.
+func HexToAddress(input string) (Address, error) {
+	b, err := HexDecode(input)
+	if err != nil {
+		return NullAddress(), fmt.Errorf("invalid address: %w", err)
+	}
+
+	if len(b) != AddressLength {
+		return NullAddress(), fmt.Errorf("invalid address: expected %v bytes, got %v", AddressLength, len(b))
+	}
+
+	// Re-encode the address, so that it is always lowercase
+	return Address(HexEncode(b)), nil
+}
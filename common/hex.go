@@ -59,3 +59,22 @@ func hexError(err error) error {
 
 	return err
 }
+
+// HexBytes is a byte slice that is encoded as a hex string with 0x prefix in text formats such as JSON
+type HexBytes []byte
+
+// MarshalText implements the encoding.TextMarshaler interface for HexBytes
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(HexEncode(b)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for HexBytes
+func (b *HexBytes) UnmarshalText(input []byte) error {
+	decoded, err := HexDecode(string(input))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
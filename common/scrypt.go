@@ -0,0 +1,153 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	// scryptN is the CPU/memory cost parameter of ScryptHash
+	scryptN = 1024
+	// scryptR is the block size parameter of ScryptHash
+	scryptR = 1
+)
+
+// ScryptHash generates a 256-bit memory-hard hash of some given data.
+// It is the scrypt key derivation function with the data as both the password and
+// the salt and the parameters N=1024, r=1, p=1, which requires 128 KiB of memory per hash.
+// These are the same parameters as the Proof of Work of Litecoin.
+func ScryptHash(data []byte) Hash {
+	return BytesToHash(scrypt(data, data, scryptN, scryptR, HashLength))
+}
+
+// scrypt derives a key of the given length from the password and salt
+// with the scrypt key derivation function for the parallelization parameter p=1
+func scrypt(password, salt []byte, n, r, keyLen int) []byte {
+	// Expand the password into a block of 128*r bytes
+	block := pbkdf2SHA256(password, salt, 128*r)
+
+	// Convert the block into little-endian words
+	x := make([]uint32, 32*r)
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(block[i*4:])
+	}
+
+	// Mix the block with the memory-hard ROMix function
+	romix(x, make([]uint32, 32*r*n), make([]uint32, 32*r), n, r)
+
+	for i, word := range x {
+		binary.LittleEndian.PutUint32(block[i*4:], word)
+	}
+
+	// Compress the mixed block into the key
+	return pbkdf2SHA256(password, block, keyLen)
+}
+
+// pbkdf2SHA256 derives a key of the given length with PBKDF2-HMAC-SHA256 with a single iteration
+func pbkdf2SHA256(password, salt []byte, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := make([]byte, 0, keyLen+prf.Size())
+
+	var counter [4]byte
+	for index := uint32(1); len(key) < keyLen; index++ {
+		binary.BigEndian.PutUint32(counter[:], index)
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter[:])
+		key = prf.Sum(key)
+	}
+
+	return key[:keyLen]
+}
+
+// romix is the sequential memory-hard mixing function of scrypt.
+// It fills v with n successive mixes of x and then mixes x with
+// n pseudo-randomly selected entries of v. y is a scratch buffer.
+func romix(x, v, y []uint32, n, r int) {
+	size := 32 * r
+
+	for i := 0; i < n; i++ {
+		copy(v[i*size:], x)
+		blockMix(x, y, r)
+	}
+
+	for i := 0; i < n; i++ {
+		j := int(x[size-16]) & (n - 1)
+		for k := 0; k < size; k++ {
+			x[k] ^= v[j*size+k]
+		}
+
+		blockMix(x, y, r)
+	}
+}
+
+// blockMix is the mixing function of scrypt for a block of 2*r
+// 64 byte chunks using the Salsa20/8 core. y is a scratch buffer.
+func blockMix(b, y []uint32, r int) {
+	var x [16]uint32
+	copy(x[:], b[(2*r-1)*16:])
+
+	for i := 0; i < 2*r; i++ {
+		for k := 0; k < 16; k++ {
+			x[k] ^= b[i*16+k]
+		}
+
+		salsa208(&x)
+
+		// Even chunks go to the first half and odd chunks to the second half
+		offset := (i/2)*16 + (i%2)*r*16
+		copy(y[offset:], x[:])
+	}
+
+	copy(b, y)
+}
+
+// salsa208 applies the Salsa20/8 core to the 16 word block in place
+func salsa208(b *[16]uint32) {
+	x := *b
+
+	for i := 0; i < 8; i += 2 {
+		// Column round
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+
+		// Row round
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+
+	for i := range b {
+		b[i] += x[i]
+	}
+}
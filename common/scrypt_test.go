@@ -0,0 +1,66 @@
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// mustDecodeHex decodes the given hex string without 0x prefix or fails the test
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid test hex %q: %v", s, err)
+	}
+
+	return b
+}
+
+// TestPBKDF2SHA256 checks pbkdf2SHA256 against the test vector in RFC 7914 Section 11 with one iteration
+func TestPBKDF2SHA256(t *testing.T) {
+	want := mustDecodeHex(t, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"+
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783")
+
+	if got := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 64); !bytes.Equal(got, want) {
+		t.Errorf("pbkdf2SHA256 = %x, want %x", got, want)
+	}
+}
+
+// TestScrypt checks scrypt against the test vectors in RFC 7914 Section 12 with a parallelization parameter of 1
+func TestScrypt(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		n, r           int
+		want           string
+	}{
+		{
+			"", "", 16, 1,
+			"77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442" +
+				"fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906",
+		},
+		{
+			"pleaseletmein", "SodiumChloride", 16384, 8,
+			"7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2" +
+				"d5432955613f0fcf62d49705242a9af9e61e85dc0d651e40dfcf017b45575887",
+		},
+	}
+
+	for _, test := range tests {
+		want := mustDecodeHex(t, test.want)
+
+		if got := scrypt([]byte(test.password), []byte(test.salt), test.n, test.r, len(want)); !bytes.Equal(got, want) {
+			t.Errorf("scrypt(%q, %q, N=%v, r=%v) = %x, want %x", test.password, test.salt, test.n, test.r, got, want)
+		}
+	}
+}
+
+// TestScryptHash checks that ScryptHash is scrypt with the data as the password and salt and N=1024, r=1, p=1
+func TestScryptHash(t *testing.T) {
+	want := "0x95c4de080da52b0b11e4b633a4dfb07ccad8aa702fa1446f01fc91b8a3f3f9db"
+
+	if got := ScryptHash([]byte("essensio")); got.Hex() != want {
+		t.Errorf("ScryptHash = %v, want %v", got.Hex(), want)
+	}
+}
@@ -1,5 +1,10 @@
 package common
 
+import (
+	"strconv"
+	"strings"
+)
+
 const (
 	// Nub is the smallest unit of a token in the Essencio Blockchain
 	Nub uint64 = 1
@@ -13,7 +18,39 @@ const (
 	// An Essence is the default unit of tokens in the Essencio Blockchain.
 	Essence = 1000 * Esse
 
-	// Quintessence is 5 Essence and is the fixed
+	// Quintessence is 5 Essence and is the initial
 	// block reward in the Essencio Blockchain
 	Quintessence = 5 * Essence
 )
+
+// Denomination represents a named unit of tokens
+type Denomination struct {
+	// Name is the name of the unit
+	Name string
+	// Nubs is the number of Nubs in one unit
+	Nubs uint64
+}
+
+// Denominations are the decimal units of tokens in ascending order
+var Denominations = []Denomination{
+	{"nub", Nub},
+	{"pith", Pith},
+	{"esse", Esse},
+	{"essence", Essence},
+}
+
+// FormatAmount returns the decimal representation of the given amount of Nubs in the
+// given unit, which must be a power of 10. For example, 1500000000 Nubs is "1.5" Essence.
+func FormatAmount(amount, unit uint64) string {
+	whole := strconv.FormatUint(amount/unit, 10)
+	if amount%unit == 0 {
+		return whole
+	}
+
+	// Pad the fraction to the number of decimals of the unit and trim its trailing zeros
+	decimals := len(strconv.FormatUint(unit, 10)) - 1
+	fraction := strconv.FormatUint(amount%unit, 10)
+	fraction = strings.Repeat("0", decimals-len(fraction)) + fraction
+
+	return whole + "." + strings.TrimRight(fraction, "0")
+}
@@ -0,0 +1,87 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/crypto"
+)
+
+var (
+	ErrInvalidTarget = errors.New("block target does not match the expected target")
+	ErrInvalidSeal   = errors.New("block seal is invalid")
+	ErrInvalidBody   = errors.New("block transactions are invalid")
+)
+
+// ChainReader is an interface for the read-only
+// access to the canonical chain used by an Engine.
+type ChainReader interface {
+	// Config returns the ChainConfig of the chain
+	Config() *core.ChainConfig
+
+	// GetHeader returns the BlockHeader of the Block with the given hash
+	GetHeader(common.Hash) (*core.BlockHeader, error)
+	// GetHeaderByHeight returns the BlockHeader of the canonical Block at the given height
+	GetHeaderByHeight(int64) (*core.BlockHeader, error)
+	// GetBlockByHeight returns the canonical Block at the given height
+	GetBlockByHeight(int64) (*core.Block, error)
+}
+
+// Engine is an interface for consensus algorithms
+// that can seal Blocks and verify sealed Blocks.
+type Engine interface {
+	// Prepare initializes the consensus fields of the header for a Block at the given height
+	Prepare(chain ChainReader, header *core.BlockHeader, height int64) error
+
+	// Finalize applies the block rewards for the given coinbase address
+	// to the Block and updates its transactions accordingly.
+	Finalize(chain ChainReader, block *core.Block, coinbase common.Address) error
+
+	// Seal generates the seal of a prepared and finalized Block and sets its hash.
+	// Returns an error if the context is cancelled before the block is sealed.
+	Seal(ctx context.Context, chain ChainReader, block *core.Block) error
+
+	// VerifyHeader checks that the consensus fields of the header
+	// for a Block at the given height conform to the rules of the engine.
+	VerifyHeader(chain ChainReader, header *core.BlockHeader, height int64) error
+}
+
+// BodyVerifier is an interface for Engines that add
+// transactions to the Blocks that they finalize.
+type BodyVerifier interface {
+	// VerifyBody checks that the transactions added by the engine into the Block are valid
+	VerifyBody(chain ChainReader, block *core.Block) error
+}
+
+// Threaded is an interface for Engines whose
+// sealing can be spread across multiple threads.
+type Threaded interface {
+	// SetThreads sets the number of threads used to seal blocks
+	SetThreads(int)
+}
+
+// Authorizable is an interface for Engines
+// that seal blocks by signing them with a key.
+type Authorizable interface {
+	// Authorize sets the private key used to sign sealed blocks
+	Authorize(crypto.PrivateKey)
+}
+
+// AccumulateRewards prepends a coinbase Transaction crediting the block reward
+// at the height of the block and the fees of its transactions to the given address.
+func AccumulateRewards(chain ChainReader, block *core.Block, coinbase common.Address) error {
+	reward, err := core.NewCoinbaseTransaction(chain.Config().ChainID, coinbase, chain.Config().BlockRewardAt(block.BlockHeight), block.BlockTxns)
+	if err != nil {
+		return fmt.Errorf("failed to create coinbase transaction: %w", err)
+	}
+
+	txns := append(core.Transactions{reward}, block.BlockTxns...)
+	if err := block.SetTransactions(txns); err != nil {
+		return fmt.Errorf("failed to add coinbase transaction: %w", err)
+	}
+
+	return nil
+}
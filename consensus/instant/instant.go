@@ -0,0 +1,69 @@
+package instant
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+// Instant is a development consensus engine that seals blocks without any work.
+// Blocks are sealed immediately or, if a period is configured, once the period
+// since the parent block has elapsed. It must never be used outside development chains.
+// It implements the consensus.Engine interface.
+type Instant struct {
+	config *core.InstantConfig
+}
+
+// New generates and returns a new Instant engine for the given config
+func New(config *core.InstantConfig) *Instant {
+	return &Instant{config}
+}
+
+// Prepare implements the consensus.Engine interface for Instant.
+// Sets the timestamp to respect the block period.
+func (instant *Instant) Prepare(chain consensus.ChainReader, header *core.BlockHeader, height int64) error {
+	if height == 0 || instant.config.Period == 0 {
+		return nil
+	}
+
+	parent, err := chain.GetHeaderByHeight(height - 1)
+	if err != nil {
+		return fmt.Errorf("parent header retrieve failed: %w", err)
+	}
+
+	// Set the timestamp to at least a period after the parent
+	if header.Timestamp < parent.Timestamp+instant.config.Period {
+		header.Timestamp = parent.Timestamp + instant.config.Period
+	}
+
+	return nil
+}
+
+// Finalize implements the consensus.Engine interface for Instant.
+// Adds the coinbase transaction with the block reward.
+func (instant *Instant) Finalize(chain consensus.ChainReader, block *core.Block, coinbase common.Address) error {
+	return consensus.AccumulateRewards(chain, block, coinbase)
+}
+
+// Seal implements the consensus.Engine interface for Instant.
+// Waits until the block timestamp and sets the block hash.
+func (instant *Instant) Seal(ctx context.Context, chain consensus.ChainReader, block *core.Block) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", core.ErrMiningCancelled, ctx.Err())
+	case <-time.After(time.Until(time.Unix(block.Timestamp, 0))):
+	}
+
+	block.BlockHash = block.Hash()
+	return nil
+}
+
+// VerifyHeader implements the consensus.Engine interface for Instant.
+// All headers are valid for the Instant engine.
+func (instant *Instant) VerifyHeader(chain consensus.ChainReader, header *core.BlockHeader, height int64) error {
+	return nil
+}
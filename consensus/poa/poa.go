@@ -0,0 +1,353 @@
+package poa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/crypto"
+)
+
+const (
+	// snapshotCacheSize is the number of recent snapshots kept in memory
+	snapshotCacheSize = 128
+	// wiggleTime is the delay per signer added to out-of-turn signing
+	// to give the in-turn signer a chance to seal its block first
+	wiggleTime = 500 * time.Millisecond
+	// allowedFutureTime is the duration by which a header timestamp may be ahead of the local clock
+	allowedFutureTime = 15 * time.Second
+)
+
+var (
+	// targetInTurn is the header Target of a block signed by the in-turn signer
+	targetInTurn = big.NewInt(2)
+	// targetNoTurn is the header Target of a block signed by an out-of-turn signer
+	targetNoTurn = big.NewInt(1)
+)
+
+var (
+	ErrNotAuthorized  = errors.New("no signer key authorized for sealing")
+	ErrFutureBlock    = errors.New("block timestamp is in the future")
+	ErrInvalidPeriod  = errors.New("block timestamp is within the period of its parent")
+	ErrEpochVote      = errors.New("vote at an epoch boundary block")
+	ErrUnknownAddress = errors.New("address has no pending proposal")
+)
+
+// PoA is the Proof of Authority consensus engine.
+// A set of authorized signers take turns sealing blocks by signing their header.
+// Signers can vote to authorize or deauthorize addresses as signers,
+// which takes effect once a majority of the signers have voted for it.
+// It implements the consensus.Engine and consensus.Authorizable interfaces.
+type PoA struct {
+	config *core.PoAConfig
+
+	// thread safety mutex
+	mu sync.RWMutex
+
+	// key is the private key used to sign blocks
+	key crypto.PrivateKey
+	// signer is the address of the key
+	signer common.Address
+	// proposals are the votes that the signer casts in the blocks it seals
+	proposals map[common.Address]bool
+
+	// snapshots is the collection of recent snapshots indexed by block hash
+	snapshots map[common.Hash]*Snapshot
+}
+
+// New generates and returns a new PoA engine for the given config
+func New(config *core.PoAConfig) *PoA {
+	return &PoA{
+		config:    config,
+		proposals: make(map[common.Address]bool),
+		snapshots: make(map[common.Hash]*Snapshot),
+	}
+}
+
+// Authorize implements the consensus.Authorizable interface for PoA.
+// Sets the private key that is used to sign sealed blocks.
+func (poa *PoA) Authorize(key crypto.PrivateKey) {
+	poa.mu.Lock()
+	defer poa.mu.Unlock()
+
+	poa.key, poa.signer = key, crypto.KeyToAddress(key)
+}
+
+// Propose adds a proposal to authorize or deauthorize the given
+// address that is voted for in the blocks sealed by this node.
+func (poa *PoA) Propose(address common.Address, authorize bool) {
+	poa.mu.Lock()
+	defer poa.mu.Unlock()
+
+	poa.proposals[address] = authorize
+}
+
+// Discard removes the pending proposal for the given address
+func (poa *PoA) Discard(address common.Address) error {
+	poa.mu.Lock()
+	defer poa.mu.Unlock()
+
+	if _, ok := poa.proposals[address]; !ok {
+		return ErrUnknownAddress
+	}
+
+	delete(poa.proposals, address)
+	return nil
+}
+
+// Proposals returns a copy of the pending proposals of this node
+func (poa *PoA) Proposals() map[common.Address]bool {
+	poa.mu.RLock()
+	defer poa.mu.RUnlock()
+
+	proposals := make(map[common.Address]bool, len(poa.proposals))
+	for address, authorize := range poa.proposals {
+		proposals[address] = authorize
+	}
+
+	return proposals
+}
+
+// Prepare implements the consensus.Engine interface for PoA.
+// Sets the timestamp to respect the block period, attaches a vote
+// from the pending proposals and sets the Target for the turn of the signer.
+func (poa *PoA) Prepare(chain consensus.ChainReader, header *core.BlockHeader, height int64) error {
+	// The genesis block is not signed
+	if height == 0 {
+		return nil
+	}
+
+	// Get the snapshot at the parent
+	snap, err := poa.Snapshot(chain, height-1)
+	if err != nil {
+		return err
+	}
+
+	parent, err := chain.GetHeaderByHeight(height - 1)
+	if err != nil {
+		return fmt.Errorf("parent header retrieve failed: %w", err)
+	}
+
+	// Set the timestamp to at least a period after the parent
+	if header.Timestamp < parent.Timestamp+poa.config.Period {
+		header.Timestamp = parent.Timestamp + poa.config.Period
+	}
+
+	poa.mu.RLock()
+	defer poa.mu.RUnlock()
+
+	// Cast a vote for a valid pending proposal, except at an epoch boundary
+	header.Extra = nil
+	if !poa.isEpoch(height) {
+		for address, authorize := range poa.proposals {
+			if snap.ValidVote(address, authorize) {
+				header.Extra = (&Vote{address, authorize}).encode()
+				break
+			}
+		}
+	}
+
+	// Set the target based on the turn of the signer
+	header.Target = targetNoTurn
+	if snap.InTurn(height, poa.signer) {
+		header.Target = targetInTurn
+	}
+
+	return nil
+}
+
+// Finalize implements the consensus.Engine interface for PoA.
+// Adds the coinbase transaction with the block reward for the signer.
+func (poa *PoA) Finalize(chain consensus.ChainReader, block *core.Block, coinbase common.Address) error {
+	return consensus.AccumulateRewards(chain, block, coinbase)
+}
+
+// Seal implements the consensus.Engine interface for PoA.
+// Waits until the block timestamp (with an additional delay for out-of-turn
+// signers), signs the header with the authorized key and sets the block hash.
+func (poa *PoA) Seal(ctx context.Context, chain consensus.ChainReader, block *core.Block) error {
+	// The genesis block is not signed
+	if block.BlockHeight == 0 {
+		block.BlockHash = block.Hash()
+		return nil
+	}
+
+	poa.mu.RLock()
+	key, signer := poa.key, poa.signer
+	poa.mu.RUnlock()
+
+	if key == nil {
+		return ErrNotAuthorized
+	}
+
+	// Check that the signer is allowed to sign the block
+	snap, err := poa.Snapshot(chain, block.BlockHeight-1)
+	if err != nil {
+		return err
+	}
+
+	if !snap.IsSigner(signer) {
+		return ErrUnauthorizedSigner
+	}
+
+	if snap.RecentlySigned(block.BlockHeight, signer) {
+		return ErrRecentlySigned
+	}
+
+	// Delay the sealing until the block timestamp
+	delay := time.Until(time.Unix(block.Timestamp, 0))
+	if !snap.InTurn(block.BlockHeight, signer) {
+		// Add a random delay for out-of-turn signers
+		wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
+		delay += time.Duration(rand.Int63n(int64(wiggle)))
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", core.ErrMiningCancelled, ctx.Err())
+	case <-time.After(delay):
+	}
+
+	// Sign the header and set the block hash
+	block.Seal = crypto.Sign(key, block.SealHash())
+	block.BlockHash = block.Hash()
+
+	return nil
+}
+
+// VerifyHeader implements the consensus.Engine interface for PoA.
+// Checks the timestamp and vote of the header, recovers the signer
+// and checks that it is authorized to sign the block in its turn.
+func (poa *PoA) VerifyHeader(chain consensus.ChainReader, header *core.BlockHeader, height int64) error {
+	// The genesis block is not signed
+	if height == 0 {
+		return nil
+	}
+
+	// Check the timestamp of the header
+	if time.Unix(header.Timestamp, 0).After(time.Now().Add(allowedFutureTime)) {
+		return ErrFutureBlock
+	}
+
+	parent, err := chain.GetHeaderByHeight(height - 1)
+	if err != nil {
+		return fmt.Errorf("parent header retrieve failed: %w", err)
+	}
+
+	if header.Timestamp < parent.Timestamp+poa.config.Period {
+		return ErrInvalidPeriod
+	}
+
+	// Check the vote of the header
+	vote, err := decodeVote(header.Extra)
+	if err != nil {
+		return err
+	}
+
+	if vote != nil && poa.isEpoch(height) {
+		return ErrEpochVote
+	}
+
+	// Recover the signer of the header
+	signer, err := crypto.Recover(header.SealHash(), header.Seal)
+	if err != nil {
+		return fmt.Errorf("%w: %v", consensus.ErrInvalidSeal, err)
+	}
+
+	// Get the snapshot at the parent
+	snap, err := poa.Snapshot(chain, height-1)
+	if err != nil {
+		return err
+	}
+
+	// Check the target matches the turn of the signer
+	expected := targetNoTurn
+	if snap.InTurn(height, signer) {
+		expected = targetInTurn
+	}
+
+	if header.Target == nil || header.Target.Cmp(expected) != 0 {
+		return consensus.ErrInvalidTarget
+	}
+
+	// Apply the header on a copy of the snapshot to check the signer and vote
+	return snap.copy().apply(height, header.Hash(), signer, vote, poa.config.Epoch)
+}
+
+// Snapshot returns the Snapshot of the authorities after the block at the given height.
+// The snapshot is built from the nearest cached snapshot or from the genesis authorities.
+func (poa *PoA) Snapshot(chain consensus.ChainReader, height int64) (*Snapshot, error) {
+	var (
+		snap    *Snapshot
+		headers []*core.BlockHeader
+	)
+
+	// Walk back from the height until a known snapshot or the genesis
+	for current := height; snap == nil; current-- {
+		header, err := chain.GetHeaderByHeight(current)
+		if err != nil {
+			return nil, fmt.Errorf("header retrieve failed: %w", err)
+		}
+
+		hash := header.Hash()
+
+		poa.mu.RLock()
+		cached, ok := poa.snapshots[hash]
+		poa.mu.RUnlock()
+
+		switch {
+		case ok:
+			snap = cached.copy()
+		case current == 0:
+			snap = newSnapshot(0, hash, poa.config.Authorities)
+		default:
+			headers = append(headers, header)
+		}
+	}
+
+	// Apply the collected headers from the oldest to the newest
+	for i := len(headers) - 1; i >= 0; i-- {
+		header := headers[i]
+
+		signer, err := crypto.Recover(header.SealHash(), header.Seal)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", consensus.ErrInvalidSeal, err)
+		}
+
+		vote, err := decodeVote(header.Extra)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := snap.apply(snap.Height+1, header.Hash(), signer, vote, poa.config.Epoch); err != nil {
+			return nil, err
+		}
+	}
+
+	poa.cacheSnapshot(snap)
+	return snap.copy(), nil
+}
+
+// cacheSnapshot adds the given Snapshot to the cache. The cache is reset once it is full.
+func (poa *PoA) cacheSnapshot(snap *Snapshot) {
+	poa.mu.Lock()
+	defer poa.mu.Unlock()
+
+	if len(poa.snapshots) >= snapshotCacheSize {
+		poa.snapshots = make(map[common.Hash]*Snapshot)
+	}
+
+	poa.snapshots[snap.Hash] = snap
+}
+
+// isEpoch returns whether the given height is an epoch boundary
+func (poa *PoA) isEpoch(height int64) bool {
+	return poa.config.Epoch > 0 && height%poa.config.Epoch == 0
+}
@@ -0,0 +1,118 @@
+package poa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/crypto"
+)
+
+// testChain is a consensus.ChainReader for a list of headers indexed by height
+type testChain struct {
+	headers []*core.BlockHeader
+}
+
+// Config implements the consensus.ChainReader interface for testChain
+func (chain *testChain) Config() *core.ChainConfig { return core.DefaultChainConfig() }
+
+// GetHeader implements the consensus.ChainReader interface for testChain
+func (chain *testChain) GetHeader(common.Hash) (*core.BlockHeader, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetHeaderByHeight implements the consensus.ChainReader interface for testChain
+func (chain *testChain) GetHeaderByHeight(height int64) (*core.BlockHeader, error) {
+	if height < 0 || height >= int64(len(chain.headers)) {
+		return nil, fmt.Errorf("block height %v out of range", height)
+	}
+
+	return chain.headers[height], nil
+}
+
+// GetBlockByHeight implements the consensus.ChainReader interface for testChain
+func (chain *testChain) GetBlockByHeight(int64) (*core.Block, error) {
+	return nil, errors.New("not implemented")
+}
+
+// testKeys returns the given number of keys sorted by their address, which is the order of their turns
+func testKeys(t *testing.T, count int) []crypto.PrivateKey {
+	t.Helper()
+
+	keys := make([]crypto.PrivateKey, count)
+	for i := range keys {
+		key, err := crypto.KeyFromSeed(bytes.Repeat([]byte{byte(i + 1)}, crypto.SeedLength))
+		if err != nil {
+			t.Fatalf("failed to create key: %v", err)
+		}
+
+		keys[i] = key
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return crypto.KeyToAddress(keys[i]) < crypto.KeyToAddress(keys[j]) })
+	return keys
+}
+
+func TestVerifyHeader(t *testing.T) {
+	keys := testKeys(t, 4)
+
+	// The first three keys are authorities, the last one is an outsider
+	authorities := make([]common.Address, 3)
+	for i := range authorities {
+		authorities[i] = crypto.KeyToAddress(keys[i])
+	}
+
+	parent := &core.BlockHeader{Timestamp: time.Now().Unix() - 60}
+	chain := &testChain{headers: []*core.BlockHeader{parent}}
+
+	// header returns a header at height 1 with the given timestamp, target and vote, signed by the given key
+	header := func(key crypto.PrivateKey, timestamp int64, target *big.Int, vote *Vote) *core.BlockHeader {
+		header := &core.BlockHeader{Timestamp: timestamp, Target: target}
+		if vote != nil {
+			header.Extra = vote.encode()
+		}
+
+		header.Seal = crypto.Sign(key, header.SealHash())
+		return header
+	}
+
+	valid := parent.Timestamp + 5
+	outsider := crypto.KeyToAddress(keys[3])
+
+	tampered := header(keys[1], valid, targetInTurn, nil)
+	tampered.Timestamp++
+
+	tests := []struct {
+		name   string
+		epoch  int64
+		header *core.BlockHeader
+		want   error
+	}{
+		{"in turn", 0, header(keys[1], valid, targetInTurn, nil), nil},
+		{"out of turn", 0, header(keys[0], valid, targetNoTurn, nil), nil},
+		{"with vote", 0, header(keys[1], valid, targetInTurn, &Vote{outsider, true}), nil},
+		{"in turn with out of turn target", 0, header(keys[1], valid, targetNoTurn, nil), consensus.ErrInvalidTarget},
+		{"out of turn with in turn target", 0, header(keys[2], valid, targetInTurn, nil), consensus.ErrInvalidTarget},
+		{"unauthorized signer", 0, header(keys[3], valid, targetNoTurn, nil), ErrUnauthorizedSigner},
+		{"tampered header", 0, tampered, consensus.ErrInvalidSeal},
+		{"within period", 0, header(keys[1], parent.Timestamp+1, targetInTurn, nil), ErrInvalidPeriod},
+		{"future block", 0, header(keys[1], time.Now().Add(time.Hour).Unix(), targetInTurn, nil), ErrFutureBlock},
+		{"vote at epoch", 1, header(keys[1], valid, targetInTurn, &Vote{outsider, true}), ErrEpochVote},
+		{"invalid vote", 0, header(keys[1], valid, targetInTurn, &Vote{authorities[0], true}), ErrInvalidVote},
+	}
+
+	for _, test := range tests {
+		engine := New(&core.PoAConfig{Period: 5, Epoch: test.epoch, Authorities: authorities})
+
+		if err := engine.VerifyHeader(chain, test.header, 1); !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package poa
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+var (
+	ErrUnauthorizedSigner = errors.New("signer is not an authority")
+	ErrRecentlySigned     = errors.New("signer has signed a recent block")
+	ErrInvalidVote        = errors.New("invalid signer vote")
+)
+
+// Snapshot represents the state of the authorities at some block height
+type Snapshot struct {
+	// Represents the height and hash of the block at which the snapshot was taken
+	Height int64
+	Hash   common.Hash
+
+	// Represents the set of authorized signers
+	Signers map[common.Address]struct{}
+	// Represents the signers of recent blocks indexed by block height
+	Recents map[int64]common.Address
+	// Represents the votes cast by signers (voter -> authorize) indexed by the voted address
+	Tally map[common.Address]map[common.Address]bool
+}
+
+// newSnapshot generates and returns a new Snapshot at the
+// given height and hash for the given set of authorities
+func newSnapshot(height int64, hash common.Hash, authorities []common.Address) *Snapshot {
+	snap := &Snapshot{
+		Height:  height,
+		Hash:    hash,
+		Signers: make(map[common.Address]struct{}),
+		Recents: make(map[int64]common.Address),
+		Tally:   make(map[common.Address]map[common.Address]bool),
+	}
+
+	for _, signer := range authorities {
+		snap.Signers[signer] = struct{}{}
+	}
+
+	return snap
+}
+
+// copy returns a deep copy of the Snapshot
+func (snap *Snapshot) copy() *Snapshot {
+	cpy := newSnapshot(snap.Height, snap.Hash, snap.SignerList())
+
+	for height, signer := range snap.Recents {
+		cpy.Recents[height] = signer
+	}
+
+	for address, votes := range snap.Tally {
+		cpy.Tally[address] = make(map[common.Address]bool, len(votes))
+		for voter, authorize := range votes {
+			cpy.Tally[address][voter] = authorize
+		}
+	}
+
+	return cpy
+}
+
+// SignerList returns the authorized signers of the Snapshot in ascending order
+func (snap *Snapshot) SignerList() []common.Address {
+	signers := make([]common.Address, 0, len(snap.Signers))
+	for signer := range snap.Signers {
+		signers = append(signers, signer)
+	}
+
+	sort.Slice(signers, func(i, j int) bool { return signers[i] < signers[j] })
+	return signers
+}
+
+// IsSigner returns whether the given address is an authorized signer
+func (snap *Snapshot) IsSigner(address common.Address) bool {
+	_, ok := snap.Signers[address]
+	return ok
+}
+
+// InTurn returns whether the given signer is the in-turn signer for the block at the given height.
+// Signers take turns in ascending order of their addresses.
+func (snap *Snapshot) InTurn(height int64, signer common.Address) bool {
+	signers := snap.SignerList()
+	if len(signers) == 0 {
+		return false
+	}
+
+	return signers[height%int64(len(signers))] == signer
+}
+
+// RecentlySigned returns whether the given signer has signed one of the recent blocks
+// that prevent it from signing a block at the given height. A signer may only sign
+// one block out of every len(signers)/2 + 1 consecutive blocks.
+func (snap *Snapshot) RecentlySigned(height int64, signer common.Address) bool {
+	limit := int64(len(snap.Signers)/2 + 1)
+
+	for recent, recentSigner := range snap.Recents {
+		if recentSigner == signer && height-recent < limit {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidVote returns whether a vote for the given address is meaningful,
+// i.e, it either authorizes a non-signer or deauthorizes a signer.
+func (snap *Snapshot) ValidVote(address common.Address, authorize bool) bool {
+	return snap.IsSigner(address) != authorize
+}
+
+// apply applies the block at the given height with the given hash, signer and vote on the Snapshot.
+// The snapshot must be at the parent of the block. The vote may be nil if the block does not carry one.
+func (snap *Snapshot) apply(height int64, hash common.Hash, signer common.Address, vote *Vote, epoch int64) error {
+	// Discard all votes at the epoch boundary
+	if epoch > 0 && height%epoch == 0 {
+		snap.Tally = make(map[common.Address]map[common.Address]bool)
+	}
+
+	// Check the signer is authorized and has not signed recently
+	if !snap.IsSigner(signer) {
+		return ErrUnauthorizedSigner
+	}
+
+	if snap.RecentlySigned(height, signer) {
+		return ErrRecentlySigned
+	}
+
+	// Record the signer and forget signers that are now outside the recents window
+	snap.Recents[height] = signer
+	snap.trimRecents(height)
+
+	// Tally the vote of the signer
+	if vote != nil {
+		if !snap.ValidVote(vote.Address, vote.Authorize) {
+			return ErrInvalidVote
+		}
+
+		if snap.Tally[vote.Address] == nil {
+			snap.Tally[vote.Address] = make(map[common.Address]bool)
+		}
+
+		snap.Tally[vote.Address][signer] = vote.Authorize
+		snap.applyVotes(vote.Address, vote.Authorize)
+	}
+
+	snap.Height, snap.Hash = height, hash
+	return nil
+}
+
+// applyVotes updates the signers if a majority of signers have voted
+// to authorize or deauthorize the given address
+func (snap *Snapshot) applyVotes(address common.Address, authorize bool) {
+	// Count the votes of current signers in favour of the proposal
+	count := 0
+	for voter, vote := range snap.Tally[address] {
+		if vote == authorize && snap.IsSigner(voter) {
+			count++
+		}
+	}
+
+	// Check if the proposal has a majority
+	if count <= len(snap.Signers)/2 {
+		return
+	}
+
+	// Apply the proposal and discard its votes
+	delete(snap.Tally, address)
+
+	if authorize {
+		snap.Signers[address] = struct{}{}
+		return
+	}
+
+	delete(snap.Signers, address)
+
+	// Discard all the votes cast by the removed signer
+	for _, votes := range snap.Tally {
+		delete(votes, address)
+	}
+}
+
+// trimRecents removes the recent signers that no longer restrict signing after the given height
+func (snap *Snapshot) trimRecents(height int64) {
+	limit := int64(len(snap.Signers)/2 + 1)
+
+	for recent := range snap.Recents {
+		if height-recent >= limit {
+			delete(snap.Recents, recent)
+		}
+	}
+}
+
+// Vote represents a proposal by a signer to authorize or deauthorize an address.
+// It is carried in the Extra field of the header of the block sealed by the signer.
+type Vote struct {
+	Address   common.Address
+	Authorize bool
+}
+
+// encode returns the encoding of the Vote for the header Extra field.
+// The first byte is 1 for authorize and 0 for deauthorize, followed by the address.
+func (vote *Vote) encode() []byte {
+	var flag byte
+	if vote.Authorize {
+		flag = 1
+	}
+
+	return append([]byte{flag}, vote.Address.Bytes()...)
+}
+
+// decodeVote decodes the Vote from the given header Extra data.
+// Returns nil if the data is empty and an error if it is malformed.
+func decodeVote(extra []byte) (*Vote, error) {
+	if len(extra) == 0 {
+		return nil, nil
+	}
+
+	if len(extra) < 2 || extra[0] > 1 {
+		return nil, ErrInvalidVote
+	}
+
+	return &Vote{common.Address(extra[1:]), extra[0] == 1}, nil
+}
@@ -0,0 +1,143 @@
+package poa
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// testBlock is a block applied on a Snapshot by the tests
+type testBlock struct {
+	signer common.Address
+	vote   *Vote
+}
+
+func TestSnapshotApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		epoch   int64
+		blocks  []testBlock
+		want    error
+		signers []common.Address
+	}{
+		{
+			"authorize with majority", 0,
+			[]testBlock{{"a", &Vote{"d", true}}, {"b", &Vote{"d", true}}},
+			nil, []common.Address{"a", "b", "c", "d"},
+		},
+		{
+			"authorize without majority", 0,
+			[]testBlock{{"a", &Vote{"d", true}}, {"b", nil}, {"c", nil}},
+			nil, []common.Address{"a", "b", "c"},
+		},
+		{
+			"deauthorize with majority", 0,
+			[]testBlock{{"a", &Vote{"c", false}}, {"b", &Vote{"c", false}}},
+			nil, []common.Address{"a", "b"},
+		},
+		{
+			"votes discarded at epoch", 2,
+			[]testBlock{{"a", &Vote{"d", true}}, {"b", &Vote{"d", true}}},
+			nil, []common.Address{"a", "b", "c"},
+		},
+		{
+			"unauthorized signer", 0,
+			[]testBlock{{"d", nil}},
+			ErrUnauthorizedSigner, nil,
+		},
+		{
+			"recently signed", 0,
+			[]testBlock{{"a", nil}, {"a", nil}},
+			ErrRecentlySigned, nil,
+		},
+		{
+			"signed outside the recents window", 0,
+			[]testBlock{{"a", nil}, {"b", nil}, {"a", nil}},
+			nil, []common.Address{"a", "b", "c"},
+		},
+		{
+			"authorize a signer", 0,
+			[]testBlock{{"a", &Vote{"b", true}}},
+			ErrInvalidVote, nil,
+		},
+		{
+			"deauthorize a non-signer", 0,
+			[]testBlock{{"a", &Vote{"d", false}}},
+			ErrInvalidVote, nil,
+		},
+	}
+
+	for _, test := range tests {
+		snap := newSnapshot(0, common.NullHash(), []common.Address{"a", "b", "c"})
+
+		var err error
+		for index, block := range test.blocks {
+			height := int64(index + 1)
+
+			if err = snap.apply(height, common.Hash256([]byte{byte(height)}), block.signer, block.vote, test.epoch); err != nil {
+				break
+			}
+		}
+
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+			continue
+		}
+
+		if test.want == nil && !reflect.DeepEqual(snap.SignerList(), test.signers) {
+			t.Errorf("%v: signers %v, want %v", test.name, snap.SignerList(), test.signers)
+		}
+	}
+}
+
+func TestSnapshotInTurn(t *testing.T) {
+	snap := newSnapshot(0, common.NullHash(), []common.Address{"c", "a", "b"})
+
+	tests := []struct {
+		height int64
+		signer common.Address
+		want   bool
+	}{
+		{0, "a", true},
+		{1, "b", true},
+		{2, "c", true},
+		{3, "a", true},
+		{1, "a", false},
+		{2, "b", false},
+		{0, "d", false},
+	}
+
+	for _, test := range tests {
+		if got := snap.InTurn(test.height, test.signer); got != test.want {
+			t.Errorf("InTurn(%v, %v) = %v, want %v", test.height, test.signer, got, test.want)
+		}
+	}
+}
+
+func TestDecodeVote(t *testing.T) {
+	tests := []struct {
+		extra []byte
+		want  *Vote
+		err   error
+	}{
+		{nil, nil, nil},
+		{(&Vote{"a", true}).encode(), &Vote{"a", true}, nil},
+		{(&Vote{"a", false}).encode(), &Vote{"a", false}, nil},
+		{[]byte{1}, nil, ErrInvalidVote},
+		{[]byte{2, 'a'}, nil, ErrInvalidVote},
+	}
+
+	for _, test := range tests {
+		vote, err := decodeVote(test.extra)
+		if !errors.Is(err, test.err) {
+			t.Errorf("decodeVote(%x): got error %v, want %v", test.extra, err, test.err)
+			continue
+		}
+
+		if !reflect.DeepEqual(vote, test.want) {
+			t.Errorf("decodeVote(%x) = %+v, want %+v", test.extra, vote, test.want)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package pos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/crypto"
+)
+
+const (
+	// registryCacheSize is the number of recent registries kept in memory
+	registryCacheSize = 128
+	// allowedFutureTime is the duration by which a header timestamp may be ahead of the local clock
+	allowedFutureTime = 15 * time.Second
+)
+
+var (
+	ErrNotAuthorized = errors.New("no validator key authorized for sealing")
+	ErrNotProposer   = errors.New("validator is not the proposer of the block")
+	ErrFutureBlock   = errors.New("block timestamp is in the future")
+	ErrInvalidPeriod = errors.New("block timestamp is within the period of its parent")
+)
+
+// PoS is the Proof of Stake consensus engine.
+// Each block is proposed and signed by a validator selected with a probability
+// proportional to its bonded stake. Validators bond and unbond stake with
+// transactions to the common.StakingAddress, as described by the Registry.
+// The stakes are enforced by the account state, which the Registry mirrors.
+// It implements the consensus.Engine, consensus.BodyVerifier and consensus.Authorizable interfaces.
+type PoS struct {
+	config *core.PoSConfig
+
+	// thread safety mutex
+	mu sync.RWMutex
+
+	// key is the private key used to sign blocks
+	key crypto.PrivateKey
+	// validator is the address of the key
+	validator common.Address
+
+	// registries is the collection of recent registries indexed by block hash
+	registries map[common.Hash]*Registry
+}
+
+// New generates and returns a new PoS engine for the given config.
+// The config must have been checked with core.ChainConfig.Validate.
+func New(config *core.PoSConfig) *PoS {
+	return &PoS{
+		config:     config,
+		registries: make(map[common.Hash]*Registry),
+	}
+}
+
+// Authorize implements the consensus.Authorizable interface for PoS.
+// Sets the private key of the validator that is used to sign proposed blocks.
+func (pos *PoS) Authorize(key crypto.PrivateKey) {
+	pos.mu.Lock()
+	defer pos.mu.Unlock()
+
+	pos.key, pos.validator = key, crypto.KeyToAddress(key)
+}
+
+// Prepare implements the consensus.Engine interface for PoS.
+// Sets the timestamp to respect the block period.
+func (pos *PoS) Prepare(chain consensus.ChainReader, header *core.BlockHeader, height int64) error {
+	// The genesis block is not proposed
+	if height == 0 {
+		return nil
+	}
+
+	parent, err := chain.GetHeaderByHeight(height - 1)
+	if err != nil {
+		return fmt.Errorf("parent header retrieve failed: %w", err)
+	}
+
+	// Set the timestamp to at least a period after the parent
+	if header.Timestamp < parent.Timestamp+pos.config.Period {
+		header.Timestamp = parent.Timestamp + pos.config.Period
+	}
+
+	return nil
+}
+
+// Finalize implements the consensus.Engine interface for PoS.
+// Adds the coinbase transaction with the block reward for the proposer and the release
+// transactions for unbonded stake that matures at the height of the block.
+// The genesis block also mints the initial stake of the validators to the common.StakingAddress.
+func (pos *PoS) Finalize(chain consensus.ChainReader, block *core.Block, coinbase common.Address) error {
+	if err := consensus.AccumulateRewards(chain, block, coinbase); err != nil {
+		return err
+	}
+
+	releases, err := pos.systemTransactions(chain, block.BlockHeight)
+	if err != nil {
+		return err
+	}
+
+	return block.SetTransactions(append(block.BlockTxns, releases...))
+}
+
+// Seal implements the consensus.Engine interface for PoS.
+// Waits until the block timestamp, signs the header with the authorized key
+// and sets the block hash. The authorized validator must be the proposer of the block.
+func (pos *PoS) Seal(ctx context.Context, chain consensus.ChainReader, block *core.Block) error {
+	// The genesis block is not proposed
+	if block.BlockHeight == 0 {
+		block.BlockHash = block.Hash()
+		return nil
+	}
+
+	pos.mu.RLock()
+	key, validator := pos.key, pos.validator
+	pos.mu.RUnlock()
+
+	if key == nil {
+		return ErrNotAuthorized
+	}
+
+	// Check that the validator is the proposer of the block
+	proposer, err := pos.Proposer(chain, block.BlockHeight)
+	if err != nil {
+		return err
+	}
+
+	if proposer != validator {
+		return ErrNotProposer
+	}
+
+	// Delay the sealing until the block timestamp
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", core.ErrMiningCancelled, ctx.Err())
+	case <-time.After(time.Until(time.Unix(block.Timestamp, 0))):
+	}
+
+	// Sign the header and set the block hash
+	block.Seal = crypto.Sign(key, block.SealHash())
+	block.BlockHash = block.Hash()
+
+	return nil
+}
+
+// VerifyHeader implements the consensus.Engine interface for PoS.
+// Checks the timestamp of the header, recovers its signer
+// and checks that it is the proposer of the block.
+func (pos *PoS) VerifyHeader(chain consensus.ChainReader, header *core.BlockHeader, height int64) error {
+	// The genesis block is not proposed
+	if height == 0 {
+		return nil
+	}
+
+	// Check the timestamp of the header
+	if time.Unix(header.Timestamp, 0).After(time.Now().Add(allowedFutureTime)) {
+		return ErrFutureBlock
+	}
+
+	parent, err := chain.GetHeaderByHeight(height - 1)
+	if err != nil {
+		return fmt.Errorf("parent header retrieve failed: %w", err)
+	}
+
+	if header.Timestamp < parent.Timestamp+pos.config.Period {
+		return ErrInvalidPeriod
+	}
+
+	// Recover the signer of the header
+	signer, err := crypto.Recover(header.SealHash(), header.Seal)
+	if err != nil {
+		return fmt.Errorf("%w: %v", consensus.ErrInvalidSeal, err)
+	}
+
+	// Check that the signer is the proposer of the block
+	proposer, err := pos.Proposer(chain, height)
+	if err != nil {
+		return err
+	}
+
+	if signer != proposer {
+		return ErrNotProposer
+	}
+
+	return nil
+}
+
+// VerifyBody implements the consensus.BodyVerifier interface for PoS.
+// Checks that the block ends with exactly the expected release transactions
+// and that no other transaction is sent from the common.StakingAddress.
+func (pos *PoS) VerifyBody(chain consensus.ChainReader, block *core.Block) error {
+	expected, err := pos.systemTransactions(chain, block.BlockHeight)
+	if err != nil {
+		return err
+	}
+
+	// Split the block transactions into the regular and the system transactions
+	if len(block.BlockTxns) < len(expected) {
+		return consensus.ErrInvalidBody
+	}
+
+	boundary := len(block.BlockTxns) - len(expected)
+	for _, txn := range block.BlockTxns[:boundary] {
+		if txn.From == common.StakingAddress() {
+			return consensus.ErrInvalidBody
+		}
+	}
+
+	for i, txn := range block.BlockTxns[boundary:] {
+		if txn.Hash() != expected[i].Hash() {
+			return consensus.ErrInvalidBody
+		}
+	}
+
+	return nil
+}
+
+// Proposer returns the validator that must propose the block at the given height
+func (pos *PoS) Proposer(chain consensus.ChainReader, height int64) (common.Address, error) {
+	registry, err := pos.Registry(chain, height-1)
+	if err != nil {
+		return common.NullAddress(), err
+	}
+
+	return registry.Proposer(height, registry.Hash, pos.config.MinimumStake)
+}
+
+// Registry returns the Registry of the validators after the block at the given height.
+// The registry is built from the nearest cached registry or from the genesis validators.
+func (pos *PoS) Registry(chain consensus.ChainReader, height int64) (*Registry, error) {
+	var (
+		registry *Registry
+		blocks   []*core.Block
+	)
+
+	// Walk back from the height until a known registry or the genesis
+	for current := height; registry == nil; current-- {
+		block, err := chain.GetBlockByHeight(current)
+		if err != nil {
+			return nil, fmt.Errorf("block retrieve failed: %w", err)
+		}
+
+		pos.mu.RLock()
+		cached, ok := pos.registries[block.BlockHash]
+		pos.mu.RUnlock()
+
+		switch {
+		case ok:
+			registry = cached.copy()
+		case current == 0:
+			registry = newRegistry(0, block.BlockHash, pos.config.Validators)
+		default:
+			blocks = append(blocks, block)
+		}
+	}
+
+	// Apply the collected blocks from the oldest to the newest
+	for i := len(blocks) - 1; i >= 0; i-- {
+		registry.apply(blocks[i].BlockHeight, blocks[i].BlockHash, blocks[i].BlockTxns, pos.config.UnbondingPeriod)
+	}
+
+	pos.cacheRegistry(registry)
+	return registry.copy(), nil
+}
+
+// systemTransactions returns the transactions that the engine appends to the block at the given height.
+// These are the initial stake of the validators for the genesis block and the matured releases otherwise.
+func (pos *PoS) systemTransactions(chain consensus.ChainReader, height int64) (core.Transactions, error) {
+	if height == 0 {
+		var stake uint64
+		for _, amount := range pos.config.Validators {
+			stake += amount
+		}
+
+		return core.Transactions{core.NewMintTransaction(chain.Config().ChainID, common.StakingAddress(), stake)}, nil
+	}
+
+	registry, err := pos.Registry(chain, height-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.Matured(chain.Config().ChainID, height), nil
+}
+
+// cacheRegistry adds the given Registry to the cache. The cache is reset once it is full.
+func (pos *PoS) cacheRegistry(registry *Registry) {
+	pos.mu.Lock()
+	defer pos.mu.Unlock()
+
+	if len(pos.registries) >= registryCacheSize {
+		pos.registries = make(map[common.Hash]*Registry)
+	}
+
+	pos.registries[registry.Hash] = registry
+}
@@ -0,0 +1,163 @@
+package pos
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+var ErrNoValidators = errors.New("no validators with the minimum stake")
+
+// Unbond represents stake that has been unbonded by a
+// validator and is locked until its release height
+type Unbond struct {
+	Address common.Address
+	Amount  uint64
+	Release int64
+}
+
+// Registry represents the state of the validators at some block height.
+//
+// A validator locks stake by sending a transaction with a non-zero value to the common.StakingAddress.
+// A transaction with a zero value to the common.StakingAddress unbonds all the stake of the sender,
+// which is released back to it by the engine after the unbonding period.
+// The account state enforces the same rules, so a Registry mirrors the stakes in the state.
+type Registry struct {
+	// Represents the height and hash of the block at which the registry was taken
+	Height int64
+	Hash   common.Hash
+
+	// Represents the bonded stake of each validator
+	Stakes map[common.Address]uint64
+	// Represents the stake that is unbonding, in order of release
+	Unbonding []Unbond
+	// Represents the number of release transactions made from the common.StakingAddress
+	Releases uint64
+}
+
+// newRegistry generates and returns a new Registry at the
+// given height and hash for the given initial stakes
+func newRegistry(height int64, hash common.Hash, stakes map[common.Address]uint64) *Registry {
+	registry := &Registry{
+		Height: height,
+		Hash:   hash,
+		Stakes: make(map[common.Address]uint64, len(stakes)),
+	}
+
+	for address, stake := range stakes {
+		registry.Stakes[address] = stake
+	}
+
+	return registry
+}
+
+// copy returns a deep copy of the Registry
+func (registry *Registry) copy() *Registry {
+	cpy := newRegistry(registry.Height, registry.Hash, registry.Stakes)
+	cpy.Unbonding = append([]Unbond{}, registry.Unbonding...)
+	cpy.Releases = registry.Releases
+
+	return cpy
+}
+
+// Validators returns the addresses with at least the given minimum stake in ascending order
+func (registry *Registry) Validators(minimum uint64) []common.Address {
+	validators := make([]common.Address, 0, len(registry.Stakes))
+	for address, stake := range registry.Stakes {
+		if stake >= minimum {
+			validators = append(validators, address)
+		}
+	}
+
+	sort.Slice(validators, func(i, j int) bool { return validators[i] < validators[j] })
+	return validators
+}
+
+// Proposer returns the validator that proposes the block at the given height on top of the given parent.
+// The proposer is selected with a probability proportional to its stake, using the hash of the
+// parent and the height as a deterministic source of randomness.
+func (registry *Registry) Proposer(height int64, parent common.Hash, minimum uint64) (common.Address, error) {
+	validators := registry.Validators(minimum)
+	if len(validators) == 0 {
+		return common.NullAddress(), ErrNoValidators
+	}
+
+	// Sum up the stake of all the validators
+	total := new(big.Int)
+	for _, validator := range validators {
+		total.Add(total, new(big.Int).SetUint64(registry.Stakes[validator]))
+	}
+
+	// Generate a random point within the total stake
+	var seed [common.HashLength + 8]byte
+	copy(seed[:], parent[:])
+	binary.BigEndian.PutUint64(seed[common.HashLength:], uint64(height))
+
+	point := common.Hash256(seed[:]).Big()
+	point.Mod(point, total)
+
+	// Find the validator whose stake range contains the point
+	cumulative := new(big.Int)
+	for _, validator := range validators {
+		cumulative.Add(cumulative, new(big.Int).SetUint64(registry.Stakes[validator]))
+		if point.Cmp(cumulative) < 0 {
+			return validator, nil
+		}
+	}
+
+	return validators[len(validators)-1], nil
+}
+
+// Matured returns the release transactions for the unbonding stake that is
+// released at the given height, for the network with the given chain ID
+func (registry *Registry) Matured(chainID uint64, height int64) core.Transactions {
+	txns := make(core.Transactions, 0)
+	nonce := registry.Releases
+
+	for _, unbond := range registry.Unbonding {
+		if unbond.Release <= height {
+			txns = append(txns, core.NewTransaction(chainID, common.StakingAddress(), unbond.Address, nonce, unbond.Amount, 0))
+			nonce++
+		}
+	}
+
+	return txns
+}
+
+// apply applies the transactions of the block at the given height with the given hash on the Registry.
+// The registry must be at the parent of the block.
+func (registry *Registry) apply(height int64, hash common.Hash, txns core.Transactions, unbondingPeriod int64) {
+	for _, txn := range txns {
+		switch {
+		// Count the release transactions of the engine
+		case txn.From == common.StakingAddress():
+			registry.Releases++
+
+		// Lock the value as stake for the sender
+		case txn.To == common.StakingAddress() && txn.Value > 0:
+			registry.Stakes[txn.From] += txn.Value
+
+		// Unbond all the stake of the sender
+		case txn.To == common.StakingAddress():
+			if stake := registry.Stakes[txn.From]; stake > 0 {
+				registry.Unbonding = append(registry.Unbonding, Unbond{txn.From, stake, height + unbondingPeriod})
+				delete(registry.Stakes, txn.From)
+			}
+		}
+	}
+
+	// Remove the unbonding stake released in this block
+	unbonding := registry.Unbonding[:0]
+	for _, unbond := range registry.Unbonding {
+		if unbond.Release > height {
+			unbonding = append(unbonding, unbond)
+		}
+	}
+
+	registry.Unbonding = unbonding
+	registry.Height, registry.Hash = height, hash
+}
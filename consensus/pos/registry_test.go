@@ -0,0 +1,158 @@
+package pos
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+func TestRegistryProposer(t *testing.T) {
+	tests := []struct {
+		name       string
+		stakes     map[common.Address]uint64
+		minimum    uint64
+		candidates []common.Address
+		want       error
+	}{
+		{"single validator", map[common.Address]uint64{"a": 100}, 100, []common.Address{"a"}, nil},
+		{"validators", map[common.Address]uint64{"a": 100, "b": 200}, 100, []common.Address{"a", "b"}, nil},
+		{"below minimum", map[common.Address]uint64{"a": 100, "b": 50}, 100, []common.Address{"a"}, nil},
+		{"no validators", map[common.Address]uint64{}, 100, nil, ErrNoValidators},
+		{"all below minimum", map[common.Address]uint64{"a": 50, "b": 99}, 100, nil, ErrNoValidators},
+	}
+
+	for _, test := range tests {
+		registry := newRegistry(0, common.NullHash(), test.stakes)
+
+		for height := int64(1); height <= 50; height++ {
+			parent := common.Hash256([]byte{byte(height)})
+
+			proposer, err := registry.Proposer(height, parent, test.minimum)
+			if !errors.Is(err, test.want) {
+				t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+				break
+			}
+
+			if test.want != nil {
+				break
+			}
+
+			if !contains(test.candidates, proposer) {
+				t.Errorf("%v: proposer %v at height %v is not one of %v", test.name, proposer, height, test.candidates)
+			}
+
+			// The proposer must be deterministic for the height and parent
+			if again, _ := registry.Proposer(height, parent, test.minimum); again != proposer {
+				t.Errorf("%v: proposer at height %v changed from %v to %v", test.name, height, proposer, again)
+			}
+		}
+	}
+}
+
+func TestRegistryProposerWeighted(t *testing.T) {
+	registry := newRegistry(0, common.NullHash(), map[common.Address]uint64{"a": 100, "b": 300})
+
+	// The validator with three times the stake must propose about three quarters of the blocks
+	counts := make(map[common.Address]int)
+	for height := int64(1); height <= 4000; height++ {
+		proposer, err := registry.Proposer(height, common.Hash256([]byte("parent")), 100)
+		if err != nil {
+			t.Fatalf("failed to select proposer: %v", err)
+		}
+
+		counts[proposer]++
+	}
+
+	if counts["b"] < 2800 || counts["b"] > 3200 {
+		t.Errorf("validator with 75%% of the stake proposed %v of 4000 blocks", counts["b"])
+	}
+}
+
+func TestRegistryApply(t *testing.T) {
+	const period = 2
+
+	tests := []struct {
+		name      string
+		txns      core.Transactions
+		stakes    map[common.Address]uint64
+		unbonding []Unbond
+	}{
+		{
+			"bond",
+			core.Transactions{core.NewTransaction(1, "b", common.StakingAddress(), 0, 50, 0)},
+			map[common.Address]uint64{"a": 100, "b": 50}, nil,
+		},
+		{
+			"bond more",
+			core.Transactions{core.NewTransaction(1, "a", common.StakingAddress(), 0, 50, 0)},
+			map[common.Address]uint64{"a": 150}, nil,
+		},
+		{
+			"unbond",
+			core.Transactions{core.NewTransaction(1, "a", common.StakingAddress(), 0, 0, 0)},
+			map[common.Address]uint64{}, []Unbond{{"a", 100, 1 + period}},
+		},
+		{
+			"unbond without stake",
+			core.Transactions{core.NewTransaction(1, "b", common.StakingAddress(), 0, 0, 0)},
+			map[common.Address]uint64{"a": 100}, nil,
+		},
+		{
+			"transfer",
+			core.Transactions{core.NewTransaction(1, "a", "b", 0, 50, 0)},
+			map[common.Address]uint64{"a": 100}, nil,
+		},
+	}
+
+	for _, test := range tests {
+		registry := newRegistry(0, common.NullHash(), map[common.Address]uint64{"a": 100})
+		registry.apply(1, common.Hash256([]byte("block")), test.txns, period)
+
+		if !reflect.DeepEqual(registry.Stakes, test.stakes) {
+			t.Errorf("%v: stakes %v, want %v", test.name, registry.Stakes, test.stakes)
+		}
+
+		if !reflect.DeepEqual(registry.Unbonding, test.unbonding) {
+			t.Errorf("%v: unbonding %v, want %v", test.name, registry.Unbonding, test.unbonding)
+		}
+	}
+}
+
+func TestRegistryMatured(t *testing.T) {
+	registry := newRegistry(0, common.NullHash(), map[common.Address]uint64{"a": 100})
+	registry.apply(1, common.NullHash(), core.Transactions{core.NewTransaction(1, "a", common.StakingAddress(), 0, 0, 0)}, 2)
+
+	// The stake is not released before the end of the unbonding period
+	if txns := registry.Matured(1, 2); len(txns) != 0 {
+		t.Fatalf("%v stake released before the unbonding period", len(txns))
+	}
+
+	txns := registry.Matured(1, 3)
+	if len(txns) != 1 {
+		t.Fatalf("%v stake released after the unbonding period, want 1", len(txns))
+	}
+
+	if want := core.NewTransaction(1, common.StakingAddress(), "a", 0, 100, 0); !reflect.DeepEqual(txns[0], want) {
+		t.Errorf("release %+v, want %+v", txns[0], want)
+	}
+
+	// Applying the release removes the unbonding stake and counts the release
+	registry.apply(3, common.NullHash(), txns, 2)
+	if len(registry.Unbonding) != 0 || registry.Releases != 1 {
+		t.Errorf("unbonding %v and %v releases after the release, want none and 1", registry.Unbonding, registry.Releases)
+	}
+}
+
+// contains returns whether the given address is in the list
+func contains(addresses []common.Address, address common.Address) bool {
+	for _, candidate := range addresses {
+		if candidate == address {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package pow
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+// PoW is the Proof of Work consensus engine.
+// It implements the consensus.Engine and consensus.Threaded interfaces.
+type PoW struct {
+	// algorithm is the hashing algorithm of the Proof of Work
+	algorithm core.PoWAlgorithm
+	// threads is the number of threads used to mine blocks
+	threads int32
+}
+
+// New generates and returns a new PoW engine for the
+// given algorithm that mines with a single thread
+func New(algorithm core.PoWAlgorithm) *PoW {
+	return &PoW{algorithm: algorithm, threads: 1}
+}
+
+// SetThreads implements the consensus.Threaded interface for PoW.
+// A thread count less than 2 uses the single-threaded Proof of Work routine.
+func (pow *PoW) SetThreads(threads int) {
+	atomic.StoreInt32(&pow.threads, int32(threads))
+}
+
+// Prepare implements the consensus.Engine interface for PoW.
+// Sets the Target of the header to the retargeted value for its height.
+func (pow *PoW) Prepare(chain consensus.ChainReader, header *core.BlockHeader, height int64) error {
+	target, err := core.CalcNextTarget(chain.Config(), chain, height)
+	if err != nil {
+		return fmt.Errorf("failed to calculate block target: %w", err)
+	}
+
+	header.Target = target
+	return nil
+}
+
+// Finalize implements the consensus.Engine interface for PoW.
+// Adds the coinbase transaction with the block reward for the miner.
+func (pow *PoW) Finalize(chain consensus.ChainReader, block *core.Block, coinbase common.Address) error {
+	return consensus.AccumulateRewards(chain, block, coinbase)
+}
+
+// Seal implements the consensus.Engine interface for PoW.
+// Mines a valid nonce for the Block and sets the block hash.
+// The genesis block is not mined, so that it is the same for every node.
+func (pow *PoW) Seal(ctx context.Context, chain consensus.ChainReader, block *core.Block) (err error) {
+	if block.BlockHeight == 0 {
+		block.BlockHash = block.Hash()
+		return nil
+	}
+
+	threads := int(atomic.LoadInt32(&pow.threads))
+
+	if threads > 1 {
+		var stats core.MiningStats
+		if block.BlockHash, stats, err = block.BlockHeader.MintParallel(ctx, pow.algorithm, threads); err != nil {
+			return err
+		}
+
+		fmt.Printf("Mined Block [%v] with %v threads: %v hashes in %v (%.2f H/s)\n",
+			block.BlockHeight, threads, stats.Hashes, stats.Duration.Round(time.Millisecond), stats.HashRate())
+
+		return nil
+	}
+
+	block.BlockHash, err = block.BlockHeader.Mint(ctx, pow.algorithm)
+	return err
+}
+
+// VerifyHeader implements the consensus.Engine interface for PoW.
+// Checks that the header has the expected target for its height and a valid Proof of Work.
+func (pow *PoW) VerifyHeader(chain consensus.ChainReader, header *core.BlockHeader, height int64) error {
+	// Calculate the expected target and check it against the header target
+	target, err := core.CalcNextTarget(chain.Config(), chain, height)
+	if err != nil {
+		return fmt.Errorf("failed to calculate expected target: %w", err)
+	}
+
+	if header.Target == nil || header.Target.Cmp(target) != 0 {
+		return consensus.ErrInvalidTarget
+	}
+
+	// Check the Proof of Work
+	if !header.Validate(pow.algorithm) {
+		return consensus.ErrInvalidSeal
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package pow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+// testChain is a consensus.ChainReader for a list of headers indexed by height
+type testChain struct {
+	config  *core.ChainConfig
+	headers []*core.BlockHeader
+}
+
+// Config implements the consensus.ChainReader interface for testChain
+func (chain *testChain) Config() *core.ChainConfig { return chain.config }
+
+// GetHeader implements the consensus.ChainReader interface for testChain
+func (chain *testChain) GetHeader(common.Hash) (*core.BlockHeader, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetHeaderByHeight implements the consensus.ChainReader interface for testChain
+func (chain *testChain) GetHeaderByHeight(height int64) (*core.BlockHeader, error) {
+	if height < 0 || height >= int64(len(chain.headers)) {
+		return nil, fmt.Errorf("block height %v out of range", height)
+	}
+
+	return chain.headers[height], nil
+}
+
+// GetBlockByHeight implements the consensus.ChainReader interface for testChain
+func (chain *testChain) GetBlockByHeight(int64) (*core.Block, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestVerifyHeader(t *testing.T) {
+	config := core.DefaultChainConfig()
+	config.GenesisDifficulty = 8
+	config.MinimumDifficulty = 8
+	config.RetargetInterval = 2
+
+	genesis := core.GenerateTarget(config.GenesisDifficulty)
+
+	// The parents of a block at height 2, produced twice as fast as the block time
+	chain := &testChain{config: config, headers: []*core.BlockHeader{
+		{Timestamp: core.DefaultGenesisTimestamp, Target: genesis},
+		{Timestamp: core.DefaultGenesisTimestamp + config.BlockTime, Target: genesis},
+	}}
+
+	engine := New(core.SHA256d{})
+
+	// mint returns a header at height 2 with the given target and a valid Proof of Work
+	mint := func(target *big.Int) *core.BlockHeader {
+		header := &core.BlockHeader{Timestamp: core.DefaultGenesisTimestamp + 2*config.BlockTime, Target: target}
+		if _, err := header.Mint(context.Background(), engine.algorithm); err != nil {
+			t.Fatalf("failed to mint header: %v", err)
+		}
+
+		return header
+	}
+
+	retargeted := new(big.Int).Div(genesis, big.NewInt(2))
+	unsealed := mint(retargeted)
+	unsealed.Nonce++
+	for unsealed.Validate(engine.algorithm) {
+		unsealed.Nonce++
+	}
+
+	tests := []struct {
+		name   string
+		header *core.BlockHeader
+		want   error
+	}{
+		{"retargeted", mint(retargeted), nil},
+		{"parent target", mint(genesis), consensus.ErrInvalidTarget},
+		{"no target", &core.BlockHeader{}, consensus.ErrInvalidTarget},
+		{"invalid proof of work", unsealed, consensus.ErrInvalidSeal},
+	}
+
+	for _, test := range tests {
+		if err := engine.VerifyHeader(chain, test.header, 2); !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+		}
+	}
+}
@@ -34,8 +34,9 @@ func (block *Block) String() string {
 	return s.String()
 }
 
-// NewBlock generates a new Block for a given set of Transactions,
-// the hash of the previous block and the block height
+// NewBlock generates a new unsealed Block for a given set of Transactions,
+// the hash of the previous block and the block height. The consensus fields
+// of the header and the block hash are set by the consensus engine that seals it.
 func NewBlock(txns Transactions, priori common.Hash, height int64) (*Block, error) {
 	block := &Block{
 		BlockTxns:   txns,
@@ -49,18 +50,51 @@ func NewBlock(txns Transactions, priori common.Hash, height int64) (*Block, erro
 	}
 
 	// Create a BlockHeader with the priori and summary
-	header := NewBlockHeader(priori, summary)
-	block.BlockHeader = header
+	block.BlockHeader = NewBlockHeader(priori, summary)
+	return block, nil
+}
+
+// GenesisBlock returns an unsealed Block that represents the Genesis Block of the given Genesis,
+// with its timestamp, extra data and a Transaction minting the balance of each account in its allocations.
+// The Coinbase Transaction of the block is added when it is finalized by the consensus engine.
+func GenesisBlock(genesis *Genesis) (*Block, error) {
+	block, err := NewBlock(genesis.Alloc.transactions(genesis.Config.ChainID), common.NullHash(), 0)
+	if err != nil {
+		return nil, err
+	}
 
-	// Mine the Block & set the block hash
-	block.BlockHash = block.BlockHeader.Mint()
+	block.Timestamp = genesis.Timestamp
+	block.Extra = append([]byte{}, genesis.ExtraData...)
 
 	return block, nil
 }
 
-// GenesisBlock returns a Block that represents a Genesis Block with just a Coinbase Transaction.
-func GenesisBlock() (*Block, error) {
-	return NewBlock(Transactions{newCoinbaseTransaction(common.MinerAddress())}, common.NullHash(), 0)
+// SetTransactions sets the given Transactions into the Block and updates the summary of the header
+func (block *Block) SetTransactions(txns Transactions) error {
+	// Generate the hash of the transactions
+	summary, err := GenerateSummary(txns)
+	if err != nil {
+		return fmt.Errorf("failed to generate transaction summary: %w", err)
+	}
+
+	block.BlockTxns, block.Summary = txns, summary
+	return nil
+}
+
+// Copy returns a deep copy of the Block that does not share its header fields or Transactions
+func (block *Block) Copy() *Block {
+	txns := make(Transactions, 0, len(block.BlockTxns))
+	for _, txn := range block.BlockTxns {
+		cpy := *txn
+		txns = append(txns, &cpy)
+	}
+
+	return &Block{
+		BlockHeader: *block.BlockHeader.Copy(),
+		BlockTxns:   txns,
+		BlockHeight: block.BlockHeight,
+		BlockHash:   block.BlockHash,
+	}
 }
 
 // TxnCount returns the number of Transaction items in the Block
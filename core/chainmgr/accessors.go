@@ -0,0 +1,249 @@
+package chainmgr
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// HeightIndexPrefix is the key prefix for the height to block hash index in the DB
+var HeightIndexPrefix = []byte("index-height-")
+
+// blockBody represents the non-header contents of a Block
+type blockBody struct {
+	txns   core.Transactions
+	height int64
+}
+
+// heightIndexKey returns the DB key for the block hash at the given height
+func heightIndexKey(height int64) []byte {
+	key := make([]byte, len(HeightIndexPrefix)+8)
+	copy(key, HeightIndexPrefix)
+	binary.BigEndian.PutUint64(key[len(HeightIndexPrefix):], uint64(height))
+
+	return key
+}
+
+// GetBlock returns a copy of the Block with the given hash, which the caller may modify.
+// The block is served from the header and body caches if possible, otherwise it is read from the DB.
+func (chain *ChainManager) GetBlock(hash common.Hash) (*core.Block, error) {
+	// Attempt to assemble the block from the caches
+	header, hok := chain.headers.Get(hash)
+	body, bok := chain.bodies.Get(hash)
+	if hok && bok {
+		block := &core.Block{BlockHeader: *header, BlockTxns: body.txns, BlockHeight: body.height, BlockHash: hash}
+		return block.Copy(), nil
+	}
+
+	// Read the block from the DB and cache it
+	block, err := chain.readBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	chain.cacheBlock(block)
+	return block, nil
+}
+
+// GetHeader returns a copy of the BlockHeader of the Block with the given hash, which the caller may modify.
+func (chain *ChainManager) GetHeader(hash common.Hash) (*core.BlockHeader, error) {
+	// Check the header cache
+	if header, ok := chain.headers.Get(hash); ok {
+		return header.Copy(), nil
+	}
+
+	// Read the block from the DB and cache it
+	block, err := chain.readBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	chain.cacheBlock(block)
+	return &block.BlockHeader, nil
+}
+
+// GetHeaderByHeight returns the BlockHeader of the canonical Block at the given height.
+// Implements the core.HeaderReader interface for ChainManager.
+func (chain *ChainManager) GetHeaderByHeight(height int64) (*core.BlockHeader, error) {
+	hash, err := chain.GetHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return chain.GetHeader(hash)
+}
+
+// GetHashByHeight returns the hash of the canonical Block at the given height.
+func (chain *ChainManager) GetHashByHeight(height int64) (common.Hash, error) {
+	if _, current := chain.CurrentHead(); height < 0 || height >= current {
+		return common.NullHash(), fmt.Errorf("block height %v out of range", height)
+	}
+
+	// Check the hash cache
+	if hash, ok := chain.hashes.Get(height); ok {
+		return hash, nil
+	}
+
+	// Read the hash from the height index in the DB
+	data, err := chain.db.GetEntry(heightIndexKey(height))
+	if err != nil {
+		return common.NullHash(), fmt.Errorf("height index retrieve failed: %w", err)
+	}
+
+	hash := common.BytesToHash(data)
+	chain.hashes.Add(height, hash)
+
+	return hash, nil
+}
+
+// GetBlockByHeight returns the canonical Block at the given height.
+func (chain *ChainManager) GetBlockByHeight(height int64) (*core.Block, error) {
+	hash, err := chain.GetHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return chain.GetBlock(hash)
+}
+
+// SetHead rewinds the chain so that the Block at the given height becomes the chain head.
+// Height index entries above the new head are removed from the DB, the blocks above it are
+// removed from the caches and the account state is reverted to its value at the new head.
+// The DB is updated in one batch, so it is left unchanged if the rewind fails.
+func (chain *ChainManager) SetHead(height int64) error {
+	// Acquire the mutex
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	// Get the hash of the new head block
+	hash, err := chain.GetHashByHeight(height)
+	if err != nil {
+		return fmt.Errorf("new chain head not found: %w", err)
+	}
+
+	// Check that the account state of all blocks above the new head can be reverted
+	for h := chain.height - 1; h > height; h-- {
+		if !state.HasDiff(chain.db, h) {
+			return fmt.Errorf("state before block %v has been pruned", h)
+		}
+	}
+
+	batch := chain.db.NewBatch()
+
+	// Revert the account state of all blocks above the new head, from the latest
+	for h := chain.height - 1; h > height; h-- {
+		if err := state.Revert(batch, h); err != nil {
+			return fmt.Errorf("state revert failed: %w", err)
+		}
+	}
+
+	// Remove the height index for all blocks above the new head
+	removed := make([]common.Hash, 0, chain.height-height-1)
+	for h := height + 1; h < chain.height; h++ {
+		stale, err := chain.GetHashByHeight(h)
+		if err != nil {
+			return err
+		}
+
+		if err := batch.DeleteEntry(heightIndexKey(h)); err != nil {
+			return fmt.Errorf("height index removal failed: %w", err)
+		}
+
+		removed = append(removed, stale)
+	}
+
+	// The next block to prune cannot be above the new head, since its
+	// state would be pruned before the block is inserted again
+	next, err := readPruned(batch)
+	if err != nil {
+		return err
+	}
+
+	if next > height+1 {
+		if err := writePruned(batch, height+1); err != nil {
+			return err
+		}
+	}
+
+	// Sync the new chain head into the DB
+	if err := writeHead(batch, hash, height+1); err != nil {
+		return err
+	}
+
+	if err := batch.Commit(); err != nil {
+		return fmt.Errorf("chain rewind commit failed: %w", err)
+	}
+
+	// Remove the blocks above the new head from the caches
+	for _, stale := range removed {
+		chain.headers.Remove(stale)
+		chain.bodies.Remove(stale)
+	}
+
+	chain.hashes.RemoveIf(func(h int64, _ common.Hash) bool { return h > height })
+
+	// Update the chain head and height
+	chain.setHead(hash, height+1)
+
+	return nil
+}
+
+// CacheMetrics returns the CacheMetrics for each of the ChainManager's caches indexed by name
+func (chain *ChainManager) CacheMetrics() map[string]CacheMetrics {
+	return map[string]CacheMetrics{
+		"headers": chain.headers.Metrics(),
+		"bodies":  chain.bodies.Metrics(),
+		"hashes":  chain.hashes.Metrics(),
+	}
+}
+
+// readBlock reads the Block with the given hash from the DB
+func (chain *ChainManager) readBlock(hash common.Hash) (*core.Block, error) {
+	// Find the Block data with the hash
+	data, err := chain.db.GetEntry(hash.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("cannot find block '%x': %w", hash, err)
+	}
+
+	// Create a new Block and deserialize the block data into it
+	block := new(core.Block)
+	if err := block.Deserialize(data); err != nil {
+		return nil, fmt.Errorf("block deserialize failed: %w", err)
+	}
+
+	return block, nil
+}
+
+// writeBlock stores the given Block into the given database and indexes it by its height.
+// The block is cached by the caller once it has been committed into the DB.
+func (chain *ChainManager) writeBlock(database *db.Database, block *core.Block) error {
+	// Serialize the Block
+	blockData, err := block.Serialize()
+	if err != nil {
+		return fmt.Errorf("block serialize failed: %w", err)
+	}
+
+	// Add block to db
+	if err := database.SetEntry(block.BlockHash.Bytes(), blockData); err != nil {
+		return fmt.Errorf("block store to db failed: %w", err)
+	}
+
+	// Add block hash to the height index
+	if err := database.SetEntry(heightIndexKey(block.BlockHeight), block.BlockHash.Bytes()); err != nil {
+		return fmt.Errorf("height index store to db failed: %w", err)
+	}
+
+	return nil
+}
+
+// cacheBlock adds the header and body of the given Block into the caches
+func (chain *ChainManager) cacheBlock(block *core.Block) {
+	// Cache a copy, so that the caller cannot modify the cached block
+	cached := block.Copy()
+	chain.headers.Add(cached.BlockHash, &cached.BlockHeader)
+	chain.bodies.Add(cached.BlockHash, blockBody{cached.BlockTxns, cached.BlockHeight})
+}
@@ -0,0 +1,253 @@
+package chainmgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// newTestChain returns a ChainManager on an in-memory database sealed by
+// the instant engine, with the given number of blocks after the genesis
+func newTestChain(t *testing.T, blocks int) *ChainManager {
+	t.Helper()
+
+	genesis := core.DefaultGenesis()
+	genesis.Config.Engine = core.EngineInstant
+	genesis.Config.Instant = &core.InstantConfig{}
+	genesis.Config.CoinbaseMaturity = 0
+
+	chain, err := NewChainManager(genesis, db.OpenMemory())
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+
+	t.Cleanup(chain.Close)
+
+	for i := 0; i < blocks; i++ {
+		if _, err := chain.AddBlock(context.Background(), nil); err != nil {
+			t.Fatalf("failed to add block %v: %v", i+1, err)
+		}
+	}
+
+	return chain
+}
+
+func TestSetHead(t *testing.T) {
+	chain := newTestChain(t, 5)
+
+	// Read every block to fill the caches
+	hashes := make([]common.Hash, 6)
+	for height := range hashes {
+		block, err := chain.GetBlockByHeight(int64(height))
+		if err != nil {
+			t.Fatalf("failed to get block %v: %v", height, err)
+		}
+
+		hashes[height] = block.BlockHash
+	}
+
+	expected, err := chain.GetAccountAt(common.MinerAddress(), hashes[2])
+	if err != nil {
+		t.Fatalf("failed to get account at block 2: %v", err)
+	}
+
+	if err := chain.SetHead(2); err != nil {
+		t.Fatalf("failed to set head: %v", err)
+	}
+
+	if head, height := chain.CurrentHead(); head != hashes[2] || height != 3 {
+		t.Fatalf("chain head is %v at height %v, want %v at height 3", head.Hex(), height, hashes[2].Hex())
+	}
+
+	// The caches must not contain the blocks above the new head
+	for height := 3; height < len(hashes); height++ {
+		if _, ok := chain.hashes.Get(int64(height)); ok {
+			t.Errorf("hash cache contains height %v above the head", height)
+		}
+
+		if _, ok := chain.headers.Get(hashes[height]); ok {
+			t.Errorf("header cache contains block %v above the head", height)
+		}
+
+		if _, ok := chain.bodies.Get(hashes[height]); ok {
+			t.Errorf("body cache contains block %v above the head", height)
+		}
+
+		if _, err := chain.GetHashByHeight(int64(height)); err == nil {
+			t.Errorf("block %v above the head is still canonical", height)
+		}
+	}
+
+	// The blocks up to the new head must still be cached
+	for height := 0; height <= 2; height++ {
+		if hash, ok := chain.hashes.Get(int64(height)); !ok || hash != hashes[height] {
+			t.Errorf("hash cache is missing height %v", height)
+		}
+	}
+
+	// The account state must be reverted to the state at the new head
+	account, err := chain.GetAccount(common.MinerAddress())
+	if err != nil {
+		t.Fatalf("failed to get account: %v", err)
+	}
+
+	if account.Balance != expected.Balance {
+		t.Errorf("balance after set head is %v, want %v", account.Balance, expected.Balance)
+	}
+
+	// The chain must grow from the new head
+	block, err := chain.AddBlock(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to add block on the new head: %v", err)
+	}
+
+	if block.BlockHeight != 3 || block.Priori != hashes[2] {
+		t.Errorf("new block at height %v on %v, want height 3 on %v", block.BlockHeight, block.Priori.Hex(), hashes[2].Hex())
+	}
+}
+
+func TestGetBlockReturnsCopies(t *testing.T) {
+	chain := newTestChain(t, 1)
+
+	hash, err := chain.GetHashByHeight(1)
+	if err != nil {
+		t.Fatalf("failed to get hash: %v", err)
+	}
+
+	// Modify the returned header and block
+	header, err := chain.GetHeader(hash)
+	if err != nil {
+		t.Fatalf("failed to get header: %v", err)
+	}
+
+	header.Timestamp++
+
+	block, err := chain.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("failed to get block: %v", err)
+	}
+
+	value := block.BlockTxns[0].Value
+
+	block.Timestamp++
+	block.BlockTxns[0].Value++
+	block.BlockTxns = block.BlockTxns[:0]
+
+	// The block must still hash to the same value
+	block, err = chain.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("failed to get block: %v", err)
+	}
+
+	if block.TxnCount() != 1 {
+		t.Fatalf("block has %v transactions, want 1", block.TxnCount())
+	}
+
+	if got := block.Hash(); got != hash {
+		t.Errorf("cached block hashes to %v, want %v", got.Hex(), hash.Hex())
+	}
+
+	if got := block.BlockTxns[0].Value; got != value {
+		t.Errorf("cached coinbase value is %v, want %v", got, value)
+	}
+}
+
+func TestSetHeadPruned(t *testing.T) {
+	chain := newTestChain(t, 0)
+	if err := chain.SetStateRetention(3); err != nil {
+		t.Fatalf("failed to set state retention: %v", err)
+	}
+
+	for i := 0; i < 6; i++ {
+		if _, err := chain.AddBlock(context.Background(), nil); err != nil {
+			t.Fatalf("failed to add block %v: %v", i+1, err)
+		}
+	}
+
+	// The states before blocks 1 to 4 are pruned
+	headers := make([]*core.BlockHeader, 7)
+	for height := range headers {
+		header, err := chain.GetHeaderByHeight(int64(height))
+		if err != nil {
+			t.Fatalf("failed to get header %v: %v", height, err)
+		}
+
+		headers[height] = header
+	}
+
+	balance := func() uint64 {
+		account, err := chain.GetAccount(common.MinerAddress())
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+
+		return account.Balance
+	}
+
+	tests := []struct {
+		height int64
+		ok     bool
+	}{
+		{2, false},
+		{3, false},
+		{4, true},
+	}
+
+	for _, test := range tests {
+		head, height := chain.CurrentHead()
+		before := balance()
+
+		err := chain.SetHead(test.height)
+		if test.ok != (err == nil) {
+			t.Fatalf("set head to %v: got error %v, want success %v", test.height, err, test.ok)
+		}
+
+		// A failed rewind must leave the chain unchanged
+		if !test.ok {
+			if current, currentHeight := chain.CurrentHead(); current != head || currentHeight != height {
+				t.Errorf("set head to %v: chain head changed after a failed rewind", test.height)
+			}
+
+			if after := balance(); after != before {
+				t.Errorf("set head to %v: balance changed from %v to %v after a failed rewind", test.height, before, after)
+			}
+		}
+	}
+
+	// The state of the new head is readable and the trie nodes of the reverted blocks are removed
+	head, err := chain.GetHashByHeight(4)
+	if err != nil {
+		t.Fatalf("failed to get new head: %v", err)
+	}
+
+	if _, err := chain.GetAccountAt(common.MinerAddress(), head); err != nil {
+		t.Errorf("state of the new head is not readable: %v", err)
+	}
+
+	for height := 5; height <= 6; height++ {
+		if _, err := state.GetAccountAt(chain.db, headers[height].StateRoot, common.MinerAddress()); !errors.Is(err, state.ErrStatePruned) {
+			t.Errorf("state of reverted block %v: got error %v, want %v", height, err, state.ErrStatePruned)
+		}
+	}
+
+	// The next block to prune must not be above the new head
+	if next, err := readPruned(chain.db); err != nil || next > 5 {
+		t.Errorf("next block to prune is %v (error %v), want at most 5", next, err)
+	}
+
+	// The chain grows and keeps pruning from the new head
+	for i := 0; i < 3; i++ {
+		if _, err := chain.AddBlock(context.Background(), nil); err != nil {
+			t.Fatalf("failed to add block on the new head: %v", err)
+		}
+	}
+
+	if _, err := chain.GetAccountAt(common.MinerAddress(), head); !errors.Is(err, state.ErrStatePruned) {
+		t.Errorf("state of block 4: got error %v, want %v", err, state.ErrStatePruned)
+	}
+}
@@ -0,0 +1,138 @@
+package chainmgr
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	// HeaderCacheSize is the number of recently accessed block headers kept in memory
+	HeaderCacheSize = 512
+	// BodyCacheSize is the number of recently accessed block bodies kept in memory
+	BodyCacheSize = 128
+	// HashCacheSize is the number of recently accessed height to hash mappings kept in memory
+	HashCacheSize = 2048
+)
+
+// CacheMetrics represents the usage counters of a single lruCache
+type CacheMetrics struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Size   int    `json:"size"`
+}
+
+// lruCache is a thread safe, size bounded Least Recently Used cache.
+// It keeps a count of cache hits and misses for metrics reporting.
+type lruCache[K comparable, V any] struct {
+	// thread safety mutex
+	mu sync.Mutex
+
+	// capacity is the maximum number of entries in the cache
+	capacity int
+	// items is the lookup of list elements indexed by their key
+	items map[K]*list.Element
+	// order is the list of entries ordered from most to least recently used
+	order *list.List
+
+	// hit and miss counters
+	hits, misses uint64
+}
+
+// lruEntry is a key-value pair stored in the lruCache order list
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// newLRUCache generates and returns a new lruCache with the given capacity
+func newLRUCache[K comparable, V any](capacity int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// Get returns the value for the given key and a boolean indicating if it was found.
+// A found entry is marked as the most recently used entry.
+func (cache *lruCache[K, V]) Get(key K) (value V, ok bool) {
+	// Acquire the mutex
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.items[key]
+	if !ok {
+		cache.misses++
+		return value, false
+	}
+
+	cache.hits++
+
+	// Move the entry to the front of the order list
+	cache.order.MoveToFront(element)
+	return element.Value.(*lruEntry[K, V]).value, true
+}
+
+// Add inserts the value for the given key into the cache.
+// If the cache is full, the least recently used entry is evicted.
+func (cache *lruCache[K, V]) Add(key K, value V) {
+	// Acquire the mutex
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	// Update the entry if it already exists
+	if element, ok := cache.items[key]; ok {
+		element.Value.(*lruEntry[K, V]).value = value
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	// Insert the new entry at the front of the order list
+	cache.items[key] = cache.order.PushFront(&lruEntry[K, V]{key, value})
+
+	// Evict the least recently used entry if over capacity
+	if cache.order.Len() > cache.capacity {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.items, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+// Remove deletes the entry for the given key from the cache
+func (cache *lruCache[K, V]) Remove(key K) {
+	// Acquire the mutex
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if element, ok := cache.items[key]; ok {
+		cache.order.Remove(element)
+		delete(cache.items, key)
+	}
+}
+
+// RemoveIf deletes all entries from the cache for which the given predicate returns true
+func (cache *lruCache[K, V]) RemoveIf(predicate func(K, V) bool) {
+	// Acquire the mutex
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key, element := range cache.items {
+		if predicate(key, element.Value.(*lruEntry[K, V]).value) {
+			cache.order.Remove(element)
+			delete(cache.items, key)
+		}
+	}
+}
+
+// Metrics returns the CacheMetrics of the cache
+func (cache *lruCache[K, V]) Metrics() CacheMetrics {
+	// Acquire the mutex
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return CacheMetrics{
+		Hits:   cache.hits,
+		Misses: cache.misses,
+		Size:   cache.order.Len(),
+	}
+}
@@ -1,11 +1,8 @@
 package chainmgr
 
 import (
-	"fmt"
-
 	"github.com/manishmeganathan/essensio/common"
 	"github.com/manishmeganathan/essensio/core"
-	"github.com/manishmeganathan/essensio/db"
 )
 
 // ChainIterator is a struct that can iterate
@@ -13,28 +10,23 @@ import (
 type ChainIterator struct {
 	// Represents the hash of the current Block on the iterator
 	cursor common.Hash
-	// Represents the database containing all Block data indexed by their hash
-	database *db.Database
+	// Represents the chain from which Blocks are retrieved
+	chain *ChainManager
 }
 
 // NewIterator constructs a new ChainIterator for the BlockChain.
 func (chain *ChainManager) NewIterator() *ChainIterator {
-	return &ChainIterator{chain.Head, chain.db}
+	head, _ := chain.CurrentHead()
+	return &ChainIterator{head, chain}
 }
 
 // Next returns the next Block in the ChainIterator.
 // Returns an error if a Block is not found or is invalid.
 func (iter *ChainIterator) Next() (*core.Block, error) {
-	// Find the Block with hash represented by the iterator cursor
-	data, err := iter.database.GetEntry(iter.cursor.Bytes())
+	// Get the Block with hash represented by the iterator cursor
+	block, err := iter.chain.GetBlock(iter.cursor)
 	if err != nil {
-		return nil, fmt.Errorf("cannot finding block '%x': %w", iter.cursor, err)
-	}
-
-	// Create a new Block and deserialize the block data into it
-	block := new(core.Block)
-	if err := block.Deserialize(data); err != nil {
-		return nil, fmt.Errorf("block deserialize failed: %w", err)
+		return nil, err
 	}
 
 	// Update the iterator cursor to the hash of the previous Block
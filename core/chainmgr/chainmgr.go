@@ -1,84 +1,349 @@
 package chainmgr
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
 
 	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus"
 	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/crypto"
 	"github.com/manishmeganathan/essensio/db"
 )
 
 var (
 	ChainHeadKey   = []byte("state-chainhead")
 	ChainHeightKey = []byte("state-chainheight")
+	// ChainConfigKey is the key for the JSON encoded ChainConfig the chain was created with
+	ChainConfigKey = []byte("state-chainconfig")
+	// StatePrunedKey is the key for the height of the next Block whose prior state is pruned
+	StatePrunedKey = []byte("state-pruned")
+)
+
+var (
+	// ErrChainStopped is returned when a Block is added to a ChainManager that has been stopped
+	ErrChainStopped = errors.New("chain manager stopped")
+	// ErrGenesisMismatch is returned when the Genesis Block in the DB was not generated by the given Genesis
+	ErrGenesisMismatch = errors.New("genesis block does not match the genesis configuration")
+	// ErrConfigMismatch is returned when the ChainConfig in the DB is not the given ChainConfig
+	ErrConfigMismatch = errors.New("chain config does not match the stored chain config")
 )
 
 // ChainManager represents a blockchain as a set of Blocks
 type ChainManager struct {
+	// Represents the configuration parameters of the chain
+	config *core.ChainConfig
+	// Represents the consensus engine used to seal and verify blocks
+	engine consensus.Engine
+	// Represents the address that receives the rewards for blocks sealed by the chain.
+	// It holds a common.Address and is read by the miner and RPC goroutines while it can be updated.
+	coinbase atomic.Value
+	// Represents the number of recent states retained in the DB, 0 retains all states
+	retain int64
+
 	// Represents the database of blockchain data
 	// This contains the state and blocks of the blockchain
 	db *db.Database
 
+	// Represents the caches of recently accessed
+	// block headers, block bodies and block hashes
+	headers *lruCache[common.Hash, *core.BlockHeader]
+	bodies  *lruCache[common.Hash, blockBody]
+	hashes  *lruCache[int64, common.Hash]
+
+	// mu serializes the updates to the chain, such as block insertions and head rewinds
+	mu sync.RWMutex
+	// headMu protects the chain head, height and head context. They are only
+	// written with both mutexes held, so code holding mu can read them directly.
+	headMu sync.RWMutex
+	// Represents the context of the ChainManager, cancelled when it is stopped
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Represents the context of the current chain head, cancelled when the head changes.
+	// In-flight mining on the current head is stopped when this context is cancelled.
+	headCtx    context.Context
+	headCancel context.CancelFunc
+	// Represents whether the ChainManager has been stopped
+	stopped bool
+
 	// Represents the hash of the last Block
-	Head common.Hash
+	head common.Hash
 	// Represents the Height of the chain. Last block Height+1
-	Height int64
+	height int64
 }
 
 // String implements the Stringer interface for BlockChain
 func (chain *ChainManager) String() string {
-	return fmt.Sprintf("Chain Head: %x || Chain Height: %v", chain.Head, chain.Height)
+	head, height := chain.CurrentHead()
+	return fmt.Sprintf("Chain Head: %x || Chain Height: %v", head, height)
+}
+
+// CurrentHead returns the hash of the last Block and the Height of the chain, which is the last block height+1
+func (chain *ChainManager) CurrentHead() (common.Hash, int64) {
+	chain.headMu.RLock()
+	defer chain.headMu.RUnlock()
+
+	return chain.head, chain.height
 }
 
-// AddBlock generates and appends a Block to the chain for a given string data.
-// The generated block is stored in the database. Any error that occurs is returned.
-func (chain *ChainManager) AddBlock(txns core.Transactions) error {
+// AddBlock generates and appends a Block to the chain for a given set of transactions.
+// The generated block is stored in the database and returned. Any error that occurs is returned.
+//
+// Mining is stopped if the given context is cancelled, if the ChainManager is stopped
+// or if the chain head changes before the block is mined, in which case an
+// error wrapping core.ErrMiningCancelled is returned.
+func (chain *ChainManager) AddBlock(ctx context.Context, txns core.Transactions) (*core.Block, error) {
 	// Create a new Block with the given transactions
-	block, err := core.NewBlock(txns, chain.Head, chain.Height)
+	block, err := chain.NewBlockTemplate(txns)
 	if err != nil {
-		return fmt.Errorf("failed to generate block: %w", err)
+		return nil, err
+	}
+
+	// Seal the Block with the consensus engine
+	if err := chain.SealBlock(ctx, block); err != nil {
+		return nil, err
+	}
+
+	// Insert the sealed Block into the chain
+	if err := chain.InsertBlock(block); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// SealBlock seals the given Block template with the consensus engine.
+// Sealing is stopped if the given context is cancelled, if the ChainManager is stopped
+// or if the chain head changes before the block is sealed, in which case an
+// error wrapping core.ErrMiningCancelled is returned.
+func (chain *ChainManager) SealBlock(ctx context.Context, block *core.Block) error {
+	// Collect the context of the chain head
+	chain.headMu.RLock()
+	head, headCtx := chain.head, chain.headCtx
+	chain.headMu.RUnlock()
+
+	if chain.ctx.Err() != nil {
+		return ErrChainStopped
+	}
+
+	// Check that the block still extends the chain head
+	if block.Priori != head {
+		return fmt.Errorf("failed to seal block: %w", ErrUnknownPriori)
+	}
+
+	// Derive a mining context that is also cancelled when the chain head changes
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-headCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Seal the Block with the consensus engine
+	if err := chain.engine.Seal(ctx, chain, block); err != nil {
+		return fmt.Errorf("failed to seal block: %w", err)
+	}
+
+	return nil
+}
+
+// NewBlockTemplate generates a new unsealed Block for the given transactions on top of the current
+// chain head. The block is prepared and finalized by the consensus engine and can be sealed externally.
+func (chain *ChainManager) NewBlockTemplate(txns core.Transactions) (*core.Block, error) {
+	// Collect the chain head to build on
+	head, height := chain.CurrentHead()
+	return chain.buildBlock(txns, head, height)
+}
+
+// InsertBlock validates the given sealed Block and appends it to the chain.
+// The block must extend the current chain head, otherwise an error wrapping ErrUnknownPriori is returned.
+func (chain *ChainManager) InsertBlock(block *core.Block) error {
+	// Acquire the mutex to update the chain head
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	if chain.stopped {
+		return ErrChainStopped
 	}
 
-	// Serialize the Block
-	blockData, err := block.Serialize()
+	// Validate the Block against the chain
+	if err := chain.validateBlock(block); err != nil {
+		return fmt.Errorf("invalid block: %w", err)
+	}
+
+	// Write the block, its account state, the pruned states and the chain head
+	// in one batch, so that the DB never contains only some of them
+	batch := chain.db.NewBatch()
+
+	// Apply the Block transactions to the account state and check the state root
+	accounts, root, err := chain.applyState(batch, block)
 	if err != nil {
-		return fmt.Errorf("block serialize failed: %w", err)
+		return fmt.Errorf("invalid block: %w", err)
+	}
+
+	if root != block.StateRoot {
+		return fmt.Errorf("invalid block: %w", ErrInvalidStateRoot)
 	}
 
 	// Add block to db
-	if err := chain.db.SetEntry(block.BlockHash.Bytes(), blockData); err != nil {
-		return fmt.Errorf("block store to db failed: %w", err)
+	if err := chain.writeBlock(batch, block); err != nil {
+		return err
+	}
+
+	// Commit the account state and prune old states
+	if _, err := accounts.Commit(block.BlockHeight); err != nil {
+		return fmt.Errorf("state commit failed: %w", err)
 	}
 
-	// Update the chain head with the new block hash and increment chain height
-	chain.Head = block.BlockHash
-	chain.Height++
+	if err := chain.pruneState(batch, block.BlockHeight); err != nil {
+		return fmt.Errorf("state prune failed: %w", err)
+	}
+
+	// Sync the new chain head into the DB
+	if err := writeHead(batch, block.BlockHash, chain.height+1); err != nil {
+		return err
+	}
 
-	// Sync the chain state into the DB
-	if err := chain.syncState(); err != nil {
-		return fmt.Errorf("chain state sync failed: %w", err)
+	if err := batch.Commit(); err != nil {
+		return fmt.Errorf("block commit failed: %w", err)
 	}
 
+	// Cache the block and update the chain head with the new block hash and incremented height
+	chain.cacheBlock(block)
+	chain.hashes.Add(block.BlockHeight, block.BlockHash)
+	chain.setHead(block.BlockHash, chain.height+1)
+
 	return nil
 }
 
-// NewChainManager returns a new BlockChain with an initialized
-// Genesis Block with the provided genesis data.
-func NewChainManager() (*ChainManager, error) {
-	// Create a new ChainManager object
-	chain := new(ChainManager)
+// buildBlock generates a new unsealed Block for the given transactions on top of the given head.
+// The block is prepared and finalized by the consensus engine and is ready to be sealed.
+func (chain *ChainManager) buildBlock(txns core.Transactions, head common.Hash, height int64) (*core.Block, error) {
+	// Create a new Block with the given transactions
+	block, err := core.NewBlock(txns, head, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate block: %w", err)
+	}
+
+	// Prepare the consensus fields of the header
+	if err := chain.engine.Prepare(chain, &block.BlockHeader, height); err != nil {
+		return nil, fmt.Errorf("failed to prepare block: %w", err)
+	}
+
+	// Finalize the block rewards
+	if err := chain.engine.Finalize(chain, block, chain.Coinbase()); err != nil {
+		return nil, fmt.Errorf("failed to finalize block: %w", err)
+	}
+
+	// Apply the block transactions to set the state root
+	if _, block.StateRoot, err = chain.applyState(chain.db, block); err != nil {
+		return nil, fmt.Errorf("failed to apply block state: %w", err)
+	}
+
+	return block, nil
+}
+
+// applyState applies the transactions of the given Block to the account state at the chain head
+// in the given database. Returns the uncommitted State and the state root after the block.
+func (chain *ChainManager) applyState(database *db.Database, block *core.Block) (*state.State, common.Hash, error) {
+	accounts := chain.newState(database)
+	if err := accounts.ApplyBlock(block, chain.config.CoinbaseMaturity); err != nil {
+		return nil, common.NullHash(), err
+	}
+
+	root, err := accounts.IntermediateRoot()
+	if err != nil {
+		return nil, common.NullHash(), err
+	}
+
+	return accounts, root, nil
+}
+
+// newState returns a new State on the given database with the staking rules of the chain.
+// Only the Proof of Stake engine has staking rules.
+func (chain *ChainManager) newState(database *db.Database) *state.State {
+	accounts := state.New(database)
+	if chain.config.Engine == core.EngineProofOfStake {
+		accounts.SetStaking(chain.config.PoS)
+	}
+
+	return accounts
+}
+
+// setHead updates the chain head and height and cancels the context of the previous head.
+// Must be called with the mutex held.
+func (chain *ChainManager) setHead(head common.Hash, height int64) {
+	chain.headMu.Lock()
+	defer chain.headMu.Unlock()
+
+	chain.head, chain.height = head, height
+
+	// Cancel any mining on the previous head and create a context for the new head
+	chain.headCancel()
+	chain.headCtx, chain.headCancel = context.WithCancel(chain.ctx)
+}
+
+// NewChainManager returns a new BlockChain on the given database. If the database does not
+// contain a chain, it is initialized with a Genesis Block generated from the given Genesis.
+func NewChainManager(genesis *core.Genesis, database *db.Database) (*ChainManager, error) {
+	// Check the parameters of the chain before creating anything
+	if err := genesis.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid chain config: %w", err)
+	}
+
+	// Create the consensus engine for the chain
+	engine, err := NewEngine(genesis.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new ChainManager object with empty caches
+	chain := &ChainManager{
+		config:  genesis.Config,
+		engine:  engine,
+		db:      database,
+		headers: newLRUCache[common.Hash, *core.BlockHeader](HeaderCacheSize),
+		bodies:  newLRUCache[common.Hash, blockBody](BodyCacheSize),
+		hashes:  newLRUCache[int64, common.Hash](HashCacheSize),
+	}
+
+	chain.coinbase.Store(common.MinerAddress())
 
-	// Check if the database already exists
-	if db.Exists() {
+	// Create the contexts for the chain and its head
+	chain.ctx, chain.cancel = context.WithCancel(context.Background())
+	chain.headCtx, chain.headCancel = context.WithCancel(chain.ctx)
+
+	// Check if the database already contains a chain
+	if database.Has(ChainHeadKey) {
 		// Load blockchain state from database
 		if err := chain.load(); err != nil {
 			return nil, fmt.Errorf("failed to load existing blockchain: %w", err)
 		}
 
+		// Check that the existing blockchain belongs to the same network
+		if err := chain.verifyGenesis(genesis); err != nil {
+			return nil, fmt.Errorf("failed to verify existing blockchain: %w", err)
+		}
+
+		// Check that the existing blockchain uses the same chain parameters
+		if err := chain.verifyConfig(); err != nil {
+			return nil, fmt.Errorf("failed to verify existing blockchain: %w", err)
+		}
+
 	} else {
 		// Initialize blockchain state and database
-		if err := chain.init(); err != nil {
-			return nil, fmt.Errorf("failed to initialize new blockchain: $%w", err)
+		if err := chain.init(genesis); err != nil {
+			return nil, fmt.Errorf("failed to initialize new blockchain: %w", err)
 		}
 	}
 
@@ -87,12 +352,7 @@ func NewChainManager() (*ChainManager, error) {
 
 // load restarts a ChainManager from the database.
 // It updates its in-memory chain state chain information from the DB.
-func (chain *ChainManager) load() (err error) {
-	// Open the database
-	if chain.db, err = db.Open(); err != nil {
-		return err
-	}
-
+func (chain *ChainManager) load() error {
 	// Get the chain head and set it
 	head, err := chain.db.GetEntry(ChainHeadKey)
 	if err != nil {
@@ -112,72 +372,419 @@ func (chain *ChainManager) load() (err error) {
 	}
 
 	// Cast the object into an int64 and set it
-	chain.Height = *object.(*int64)
+	chain.height = *object.(*int64)
 	// Convert the head bytes into a Hash and set it
-	chain.Head = common.BytesToHash(head)
+	chain.head = common.BytesToHash(head)
+
+	// Rebuild the height index if the database predates it
+	if _, err := chain.db.GetEntry(heightIndexKey(chain.height - 1)); err != nil {
+		if err := chain.reindex(); err != nil {
+			return fmt.Errorf("height index rebuild failed: %w", err)
+		}
+	}
+
+	// Rebuild the account state if the database predates it
+	if !chain.db.Has(state.StateRootKey) {
+		if err := chain.rebuildState(); err != nil {
+			return fmt.Errorf("account state rebuild failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// reindex walks the chain from the head to the genesis and
+// stores the height to block hash index for each Block into the DB.
+func (chain *ChainManager) reindex() error {
+	iterator := chain.NewIterator()
+	for !iterator.Done() {
+		// Get the next block
+		block, err := iterator.Next()
+		if err != nil {
+			return err
+		}
+
+		// Add block hash to the height index
+		if err := chain.db.SetEntry(heightIndexKey(block.BlockHeight), block.BlockHash.Bytes()); err != nil {
+			return fmt.Errorf("height index store to db failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rebuildState replays the transactions of every Block from
+// the genesis to the head and commits the account state into the DB.
+func (chain *ChainManager) rebuildState() error {
+	for height := int64(0); height < chain.height; height++ {
+		// Get the block at the height
+		block, err := chain.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+
+		// Apply and commit the block transactions
+		accounts := chain.newState(chain.db)
+		if err := accounts.ApplyBlock(block, chain.config.CoinbaseMaturity); err != nil {
+			return fmt.Errorf("block %v state apply failed: %w", height, err)
+		}
+
+		if _, err := accounts.Commit(height); err != nil {
+			return fmt.Errorf("block %v state commit failed: %w", height, err)
+		}
+	}
 
 	return nil
 }
 
 // init initializes a new chain in the database.
 // It generates a Genesis Block and adds it to DB and updates all chain state data.
-func (chain *ChainManager) init() (err error) {
-	// Open the database
-	if chain.db, err = db.Open(); err != nil {
+func (chain *ChainManager) init(genesis *core.Genesis) error {
+	fmt.Println(">>>> New Blockchain Initialization. Creating Genesis Block <<<<")
+
+	// Write the genesis block, its account state, the chain config and the chain head in one batch
+	batch := chain.db.NewBatch()
+
+	// Create the Genesis Block with its account state
+	genesisBlock, accounts, err := chain.genesisBlock(genesis, batch)
+	if err != nil {
 		return err
 	}
 
-	fmt.Println(">>>> New Blockchain Initialization. Creating Genesis Block <<<<")
+	// Add Genesis Block to DB
+	if err := chain.writeBlock(batch, genesisBlock); err != nil {
+		return fmt.Errorf("genesis block store to db failed: %w", err)
+	}
+
+	// Commit the genesis account state
+	if _, err := accounts.Commit(0); err != nil {
+		return fmt.Errorf("genesis state commit failed: %w", err)
+	}
 
+	// Add the chain config to DB
+	if err := chain.writeConfig(batch); err != nil {
+		return err
+	}
+
+	// Sync the chain head into the DB
+	if err := writeHead(batch, genesisBlock.BlockHash, 1); err != nil {
+		return err
+	}
+
+	if err := batch.Commit(); err != nil {
+		return fmt.Errorf("genesis commit failed: %w", err)
+	}
+
+	// Cache the genesis block and set the chain height and head into struct
+	chain.cacheBlock(genesisBlock)
+	chain.hashes.Add(0, genesisBlock.BlockHash)
+	chain.head, chain.height = genesisBlock.BlockHash, 1
+
+	return nil
+}
+
+// genesisBlock generates the sealed Genesis Block for the given Genesis along with its uncommitted
+// account state, which is applied on the given database. The same Genesis always generates the same block.
+func (chain *ChainManager) genesisBlock(genesis *core.Genesis, database *db.Database) (*core.Block, *state.State, error) {
 	// Create Genesis Block
-	genesisBlock, err := core.GenesisBlock()
+	genesisBlock, err := core.GenesisBlock(genesis)
 	if err != nil {
-		return fmt.Errorf("genesis block generation failed: %w", err)
+		return nil, nil, fmt.Errorf("genesis block generation failed: %w", err)
+	}
+
+	// Prepare and finalize the Genesis Block
+	if err := chain.engine.Prepare(chain, &genesisBlock.BlockHeader, 0); err != nil {
+		return nil, nil, fmt.Errorf("genesis block prepare failed: %w", err)
+	}
+
+	if err := chain.engine.Finalize(chain, genesisBlock, genesis.Coinbase); err != nil {
+		return nil, nil, fmt.Errorf("genesis block finalize failed: %w", err)
+	}
+
+	// Apply the Genesis Block allocations to the account state and set the state root
+	accounts := chain.newState(database)
+	if err := accounts.ApplyBlock(genesisBlock, chain.config.CoinbaseMaturity); err != nil {
+		return nil, nil, fmt.Errorf("genesis state apply failed: %w", err)
+	}
+
+	if genesisBlock.StateRoot, err = accounts.IntermediateRoot(); err != nil {
+		return nil, nil, fmt.Errorf("genesis state apply failed: %w", err)
 	}
 
-	// Serialize the Genesis Block
-	genesisData, err := genesisBlock.Serialize()
+	// Seal the Genesis Block
+	if err := chain.engine.Seal(chain.ctx, chain, genesisBlock); err != nil {
+		return nil, nil, fmt.Errorf("genesis block seal failed: %w", err)
+	}
+
+	return genesisBlock, accounts, nil
+}
+
+// verifyGenesis checks that the Genesis Block in the database is the block generated by the given Genesis
+func (chain *ChainManager) verifyGenesis(genesis *core.Genesis) error {
+	stored, err := chain.GetHashByHeight(0)
 	if err != nil {
-		return fmt.Errorf("block serialize failed: %w", err)
+		return fmt.Errorf("genesis block retrieve failed: %w", err)
 	}
 
-	// Add Genesis Block to DB
-	if err := chain.db.SetEntry(genesisBlock.BlockHash.Bytes(), genesisData); err != nil {
-		return fmt.Errorf("genesis block store to db failed: %w", err)
+	// Generate the expected Genesis Block on a throwaway database
+	expected, _, err := chain.genesisBlock(genesis, db.OpenMemory())
+	if err != nil {
+		return err
+	}
+
+	if stored != expected.BlockHash {
+		return fmt.Errorf("%w: stored %v, expected %v", ErrGenesisMismatch, stored.Hex(), expected.BlockHash.Hex())
+	}
+
+	return nil
+}
+
+// verifyConfig checks that the ChainConfig in the database is the ChainConfig of the chain.
+// The configs are compared after decoding, so that a difference in the encoding alone is not a mismatch.
+// The config is stored if the database predates it.
+func (chain *ChainManager) verifyConfig() error {
+	if !chain.db.Has(ChainConfigKey) {
+		return chain.writeConfig(chain.db)
 	}
 
-	// Set the chain height and head into struct
-	chain.Head, chain.Height = genesisBlock.BlockHash, 1
+	data, err := chain.db.GetEntry(ChainConfigKey)
+	if err != nil {
+		return fmt.Errorf("chain config retrieve failed: %w", err)
+	}
+
+	stored := new(core.ChainConfig)
+	if err := json.Unmarshal(data, stored); err != nil {
+		return fmt.Errorf("error deserializing chain config: %w", err)
+	}
+
+	// Round trip the config of the chain through JSON, so that both configs are decoded the same way
+	encoded, err := json.Marshal(chain.config)
+	if err != nil {
+		return fmt.Errorf("error serializing chain config: %w", err)
+	}
+
+	expected := new(core.ChainConfig)
+	if err := json.Unmarshal(encoded, expected); err != nil {
+		return fmt.Errorf("error deserializing chain config: %w", err)
+	}
 
-	// Sync the chain state into the DB
-	if err := chain.syncState(); err != nil {
-		return fmt.Errorf("chain state sync failed: %w", err)
+	if !reflect.DeepEqual(stored, expected) {
+		return fmt.Errorf("%w: stored %s, expected %s", ErrConfigMismatch, data, encoded)
 	}
 
 	return nil
 }
 
-// Stop closes the ChainManager's database client
+// writeConfig stores the ChainConfig of the chain into the given database at the ChainConfigKey
+func (chain *ChainManager) writeConfig(database *db.Database) error {
+	data, err := json.Marshal(chain.config)
+	if err != nil {
+		return fmt.Errorf("error serializing chain config: %w", err)
+	}
+
+	if err := database.SetEntry(ChainConfigKey, data); err != nil {
+		return fmt.Errorf("chain config store to db failed: %w", err)
+	}
+
+	return nil
+}
+
+// Config returns the ChainConfig of the chain.
+// Implements the consensus.ChainReader interface for ChainManager.
+func (chain *ChainManager) Config() *core.ChainConfig {
+	return chain.config
+}
+
+// Engine returns the consensus engine of the chain
+func (chain *ChainManager) Engine() consensus.Engine {
+	return chain.engine
+}
+
+// State returns a view of the account state at the chain head,
+// at which transactions are applied for the next Block of the chain
+func (chain *ChainManager) State() *state.State {
+	_, height := chain.CurrentHead()
+
+	accounts := chain.newState(chain.db)
+	accounts.SetHeight(height)
+
+	return accounts
+}
+
+// GetAccount returns the Account of the given address at the chain head
+func (chain *ChainManager) GetAccount(address common.Address) (*state.Account, error) {
+	return chain.State().GetAccount(address)
+}
+
+// GetAccountAt returns the Account of the given address after the Block with the given hash.
+// Returns an error wrapping state.ErrStatePruned if the state of the block has been pruned.
+func (chain *ChainManager) GetAccountAt(address common.Address, hash common.Hash) (*state.Account, error) {
+	header, err := chain.GetHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return state.GetAccountAt(chain.db, header.StateRoot, address)
+}
+
+// SetStateRetention sets the number of recent states retained in the DB and prunes all older states.
+// A retention of 0 runs the chain in archive mode, retaining the state after every Block.
+func (chain *ChainManager) SetStateRetention(retain int64) error {
+	// Acquire the mutex
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	if retain < 0 {
+		retain = 0
+	}
+
+	chain.retain = retain
+
+	// Prune the states in one batch, so that the pruned height is synced along with them
+	batch := chain.db.NewBatch()
+	if err := chain.pruneState(batch, chain.height-1); err != nil {
+		return err
+	}
+
+	return batch.Commit()
+}
+
+// pruneState prunes the states that are older than the retained number of states before
+// the given head height from the given database. Must be called with the mutex held.
+func (chain *ChainManager) pruneState(database *db.Database, head int64) error {
+	if chain.retain == 0 {
+		return nil
+	}
+
+	// Get the height of the next block to prune
+	next, err := readPruned(database)
+	if err != nil {
+		return err
+	}
+
+	// The oldest retained state is after the block at head-retain+1,
+	// so the states before it and all older blocks are pruned
+	limit := head - chain.retain + 1
+	if next > limit {
+		return nil
+	}
+
+	for ; next <= limit; next++ {
+		if err := state.Prune(database, next); err != nil {
+			return fmt.Errorf("block %v state prune failed: %w", next, err)
+		}
+	}
+
+	return writePruned(database, next)
+}
+
+// readPruned returns the height of the next block whose prior state is pruned from the given database.
+// Pruning starts at block 1, whose prior state is the genesis state, so the genesis state is pruned too.
+func readPruned(database *db.Database) (int64, error) {
+	data, err := database.GetEntry(StatePrunedKey)
+	if err != nil {
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return 1, nil
+		}
+
+		return 0, fmt.Errorf("pruned height retrieve failed: %w", err)
+	}
+
+	object, err := common.GobDecode(data, new(int64))
+	if err != nil {
+		return 0, fmt.Errorf("error deserializing pruned height: %w", err)
+	}
+
+	return *object.(*int64), nil
+}
+
+// writePruned syncs the height of the next block whose prior state is pruned into the given database
+func writePruned(database *db.Database, next int64) error {
+	data, err := common.GobEncode(next)
+	if err != nil {
+		return fmt.Errorf("error serializing pruned height: %w", err)
+	}
+
+	if err := database.SetEntry(StatePrunedKey, data); err != nil {
+		return fmt.Errorf("error syncing pruned height: %w", err)
+	}
+
+	return nil
+}
+
+// ProveAccount returns the Account of the given address and its Proof
+// against the StateRoot of the Block with the given hash
+func (chain *ChainManager) ProveAccount(address common.Address, hash common.Hash) (*state.Account, *state.Proof, error) {
+	header, err := chain.GetHeader(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return state.Prove(chain.db, header.StateRoot, address)
+}
+
+// Authorize sets the key of the node for engines that seal blocks by signing them.
+// The address of the key also becomes the coinbase that receives the block rewards.
+func (chain *ChainManager) Authorize(key crypto.PrivateKey) {
+	chain.coinbase.Store(crypto.KeyToAddress(key))
+
+	if engine, ok := chain.engine.(consensus.Authorizable); ok {
+		engine.Authorize(key)
+	}
+}
+
+// Coinbase returns the address that receives the rewards for blocks sealed by the chain
+func (chain *ChainManager) Coinbase() common.Address {
+	return chain.coinbase.Load().(common.Address)
+}
+
+// SetMinerThreads sets the number of threads used to seal blocks.
+// It has no effect if the consensus engine does not support multiple threads.
+func (chain *ChainManager) SetMinerThreads(threads int) {
+	if engine, ok := chain.engine.(consensus.Threaded); ok {
+		engine.SetThreads(threads)
+	}
+}
+
+// Stop cancels any in-flight mining and waits for any in-progress block insertion.
+// Blocks can no longer be added once it is stopped, but the chain can still be read until it is closed.
 func (chain *ChainManager) Stop() {
+	// Cancel the chain context to stop all mining
+	chain.cancel()
+
+	// Acquire the mutex to wait for any in-progress block insertion
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	chain.stopped = true
+}
+
+// Close stops the ChainManager and closes its database client.
+// The chain must not be read once it is closed.
+func (chain *ChainManager) Close() {
+	chain.Stop()
+
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
 	chain.db.Close()
 }
 
-// syncState updates the chain head and height values into the DB at keys
-// specified by the ChainHeadKey and ChainHeightKey respectively.
-func (chain *ChainManager) syncState() error {
+// writeHead syncs the given chain head and height into the given database
+func writeHead(database *db.Database, head common.Hash, height int64) error {
 	// Sync chain head into the DB
-	if err := chain.db.SetEntry(ChainHeadKey, chain.Head.Bytes()); err != nil {
+	if err := database.SetEntry(ChainHeadKey, head.Bytes()); err != nil {
 		return fmt.Errorf("error syncing chain head: %w", err)
 	}
 
 	// Serialize the chain height
-	height, err := common.GobEncode(chain.Height)
+	data, err := common.GobEncode(height)
 	if err != nil {
 		return fmt.Errorf("error serializing chain height: %w", err)
 	}
 
 	// Sync the encoded height into the DB
-	if err := chain.db.SetEntry(ChainHeightKey, height); err != nil {
+	if err := database.SetEntry(ChainHeightKey, data); err != nil {
 		return fmt.Errorf("error syncing chain height: %w", err)
 	}
 
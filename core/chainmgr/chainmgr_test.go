@@ -0,0 +1,65 @@
+package chainmgr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+func TestNewChainManagerGenesis(t *testing.T) {
+	// genesis returns the Genesis of the test chain, modified by the given function
+	genesis := func(modify func(genesis *core.Genesis)) *core.Genesis {
+		genesis := core.DefaultGenesis()
+		genesis.Config.Engine = core.EngineInstant
+		genesis.Config.Instant = &core.InstantConfig{}
+		genesis.Alloc = core.GenesisAlloc{"alice": 100}
+
+		modify(genesis)
+		return genesis
+	}
+
+	tests := []struct {
+		name   string
+		modify func(genesis *core.Genesis)
+		want   error
+	}{
+		{"same genesis", func(genesis *core.Genesis) {}, nil},
+		{"different allocations", func(genesis *core.Genesis) { genesis.Alloc["bob"] = 1 }, ErrGenesisMismatch},
+		{"different timestamp", func(genesis *core.Genesis) { genesis.Timestamp++ }, ErrGenesisMismatch},
+		{"different chain id", func(genesis *core.Genesis) { genesis.Config.ChainID++ }, ErrGenesisMismatch},
+		{"different halving interval", func(genesis *core.Genesis) { genesis.Config.HalvingInterval++ }, ErrConfigMismatch},
+		{"different max supply", func(genesis *core.Genesis) { genesis.Config.MaxSupply++ }, ErrConfigMismatch},
+		{"different maturity", func(genesis *core.Genesis) { genesis.Config.CoinbaseMaturity++ }, ErrGenesisMismatch},
+	}
+
+	for _, test := range tests {
+		database := db.OpenMemory()
+
+		chain, err := NewChainManager(genesis(func(*core.Genesis) {}), database)
+		if err != nil {
+			t.Fatalf("%v: failed to create chain: %v", test.name, err)
+		}
+
+		// Restart the chain on the same database with the modified genesis
+		chain.Stop()
+
+		restarted, err := NewChainManager(genesis(test.modify), database)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+		}
+
+		if err == nil {
+			restarted.Stop()
+		}
+
+		database.Close()
+	}
+
+	// An invalid chain config is rejected before the database is used
+	invalid := genesis(func(genesis *core.Genesis) { genesis.Config.ChainID = 0 })
+	if _, err := NewChainManager(invalid, db.OpenMemory()); err == nil {
+		t.Errorf("expected error for an invalid chain config")
+	}
+}
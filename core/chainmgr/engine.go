@@ -0,0 +1,46 @@
+package chainmgr
+
+import (
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/consensus"
+	"github.com/manishmeganathan/essensio/consensus/instant"
+	"github.com/manishmeganathan/essensio/consensus/poa"
+	"github.com/manishmeganathan/essensio/consensus/pos"
+	"github.com/manishmeganathan/essensio/consensus/pow"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+// NewEngine returns the consensus engine selected by the given ChainConfig.
+// Returns an error if the config specifies an unknown engine.
+func NewEngine(config *core.ChainConfig) (consensus.Engine, error) {
+	switch config.Engine {
+	case core.EngineProofOfWork:
+		algorithm, err := core.NewPoWAlgorithm(config.PoWAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		return pow.New(algorithm), nil
+	case core.EngineProofOfAuthority:
+		if config.PoA == nil {
+			return nil, fmt.Errorf("missing proof of authority config")
+		}
+
+		return poa.New(config.PoA), nil
+	case core.EngineProofOfStake:
+		if config.PoS == nil {
+			return nil, fmt.Errorf("missing proof of stake config")
+		}
+
+		return pos.New(config.PoS), nil
+	case core.EngineInstant:
+		if config.Instant == nil {
+			return nil, fmt.Errorf("missing instant config")
+		}
+
+		return instant.New(config.Instant), nil
+	default:
+		return nil, fmt.Errorf("unknown consensus engine '%v'", config.Engine)
+	}
+}
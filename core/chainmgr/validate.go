@@ -0,0 +1,95 @@
+package chainmgr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/consensus"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+)
+
+var (
+	ErrUnknownPriori    = errors.New("block priori is not the chain head")
+	ErrInvalidHeight    = errors.New("block height does not follow the chain height")
+	ErrInvalidHash      = errors.New("block hash does not match the block header")
+	ErrInvalidSummary   = errors.New("block summary does not match the block transactions")
+	ErrInvalidStateRoot = errors.New("block state root does not match the state after the block")
+	ErrInvalidCoinbase  = errors.New("block coinbase does not match the block reward and fees")
+)
+
+// validateBlock checks that the given Block can be appended to the chain.
+// The block must extend the chain head, have a header that is valid for the
+// consensus engine, transactions for the chain ID of the chain, a coinbase for
+// the block reward and fees and a summary that matches its transactions.
+func (chain *ChainManager) validateBlock(block *core.Block) error {
+	// Check that the block extends the chain head
+	if block.Priori != chain.head {
+		return ErrUnknownPriori
+	}
+
+	// Check that the block height follows the chain height
+	if block.BlockHeight != chain.height {
+		return ErrInvalidHeight
+	}
+
+	// Check that the block hash is the hash of the header
+	if block.BlockHash != block.Hash() {
+		return ErrInvalidHash
+	}
+
+	// Verify the consensus fields of the header
+	if err := chain.engine.VerifyHeader(chain, &block.BlockHeader, block.BlockHeight); err != nil {
+		return err
+	}
+
+	// Verify the transactions added by the consensus engine
+	if engine, ok := chain.engine.(consensus.BodyVerifier); ok {
+		if err := engine.VerifyBody(chain, block); err != nil {
+			return err
+		}
+	}
+
+	// Check that every transaction is for the network of the chain
+	for index, txn := range block.BlockTxns {
+		if txn.ChainID != chain.config.ChainID {
+			return fmt.Errorf("%w: transaction %v", core.ErrInvalidChainID, index)
+		}
+	}
+
+	// Check that the coinbase credits exactly the block reward and the fees of the block
+	if err := chain.verifyCoinbase(block); err != nil {
+		return err
+	}
+
+	// Check the summary of the transactions
+	summary, err := core.GenerateSummary(block.BlockTxns)
+	if err != nil {
+		return fmt.Errorf("failed to generate transaction summary: %w", err)
+	}
+
+	if block.Summary != summary {
+		return ErrInvalidSummary
+	}
+
+	return nil
+}
+
+// verifyCoinbase checks that the first transaction of the given Block is a mint transaction
+// for the block reward at the height of the block plus the fees of the other transactions
+func (chain *ChainManager) verifyCoinbase(block *core.Block) error {
+	if len(block.BlockTxns) == 0 || !state.IsMint(block.BlockTxns[0]) {
+		return ErrInvalidCoinbase
+	}
+
+	value, err := core.CoinbaseValue(chain.config.BlockRewardAt(block.BlockHeight), block.BlockTxns[1:])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCoinbase, err)
+	}
+
+	if coinbase := block.BlockTxns[0]; coinbase.Value != value || coinbase.Fee != 0 {
+		return ErrInvalidCoinbase
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package chainmgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+func TestInsertBlock(t *testing.T) {
+	// transfer is a transaction from the miner, which has the rewards of the first blocks
+	transfer := func(chainID uint64) *core.Transaction {
+		return core.NewTransaction(chainID, common.MinerAddress(), "receiver", 0, 100, 10)
+	}
+
+	tests := []struct {
+		name   string
+		txns   bool
+		modify func(block *core.Block)
+		want   error
+	}{
+		{"valid block", false, func(block *core.Block) {}, nil},
+		{"valid block with fees", true, func(block *core.Block) {}, nil},
+		{"state root of another state", false, func(block *core.Block) { block.StateRoot = common.Hash256([]byte("state")) }, ErrInvalidStateRoot},
+		{"state root of the parent", false, func(block *core.Block) { block.StateRoot = common.NullHash() }, ErrInvalidStateRoot},
+		{"coinbase without the fees", true, func(block *core.Block) { block.BlockTxns[0].Value -= 10 }, ErrInvalidCoinbase},
+		{"coinbase above the reward", false, func(block *core.Block) { block.BlockTxns[0].Value++ }, ErrInvalidCoinbase},
+		{"coinbase with a fee", false, func(block *core.Block) { block.BlockTxns[0].Fee = 1 }, ErrInvalidCoinbase},
+		{"missing coinbase", true, func(block *core.Block) { block.BlockTxns = block.BlockTxns[1:] }, ErrInvalidCoinbase},
+		{"coinbase after a transfer", true, func(block *core.Block) {
+			block.BlockTxns[0], block.BlockTxns[1] = block.BlockTxns[1], block.BlockTxns[0]
+		}, ErrInvalidCoinbase},
+	}
+
+	for _, test := range tests {
+		chain := newTestChain(t, 1)
+		head, height := chain.CurrentHead()
+
+		var txns core.Transactions
+		if test.txns {
+			txns = core.Transactions{transfer(chain.config.ChainID)}
+		}
+
+		before, err := chain.GetAccount(common.MinerAddress())
+		if err != nil {
+			t.Fatalf("%v: failed to get account: %v", test.name, err)
+		}
+
+		block, err := chain.NewBlockTemplate(txns)
+		if err != nil {
+			t.Fatalf("%v: failed to create block: %v", test.name, err)
+		}
+
+		test.modify(block)
+
+		if err := chain.SealBlock(context.Background(), block); err != nil {
+			t.Fatalf("%v: failed to seal block: %v", test.name, err)
+		}
+
+		err = chain.InsertBlock(block)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+			continue
+		}
+
+		// A rejected block must not change the chain head
+		if test.want != nil {
+			if current, currentHeight := chain.CurrentHead(); current != head || currentHeight != height {
+				t.Errorf("%v: chain head changed by a rejected block", test.name)
+			}
+
+			continue
+		}
+
+		if current, _ := chain.CurrentHead(); current != block.BlockHash {
+			t.Errorf("%v: chain head is %v, want %v", test.name, current.Hex(), block.BlockHash.Hex())
+		}
+
+		// The miner pays the value and fee of its transfer and is credited the block reward and the fee
+		want := before.Balance + chain.config.BlockRewardAt(block.BlockHeight)
+		if test.txns {
+			want -= transfer(chain.config.ChainID).Value
+		}
+
+		if after, _ := chain.GetAccount(common.MinerAddress()); after.Balance != want {
+			t.Errorf("%v: miner balance is %v, want %v", test.name, after.Balance, want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+const (
+	// EngineProofOfWork is the name of the Proof of Work consensus engine
+	EngineProofOfWork = "pow"
+	// EngineProofOfAuthority is the name of the Proof of Authority consensus engine
+	EngineProofOfAuthority = "poa"
+	// EngineProofOfStake is the name of the Proof of Stake consensus engine
+	EngineProofOfStake = "pos"
+	// EngineInstant is the name of the development consensus engine that seals blocks without any work
+	EngineInstant = "instant"
+)
+
+// DefaultChainID is the chain identifier of the default Essensio network
+const DefaultChainID = 1
+
+// ChainConfig represents the configurable parameters of the blockchain
+type ChainConfig struct {
+	// ChainID is the identifier of the network of the chain
+	ChainID uint64 `json:"chain_id"`
+
+	// Engine is the name of the consensus engine used to seal and verify blocks
+	Engine string `json:"engine"`
+	// PoWAlgorithm is the name of the hashing algorithm of the Proof of Work engine
+	PoWAlgorithm string `json:"pow_algorithm"`
+
+	// BlockTime is the expected duration between blocks in seconds
+	BlockTime int64 `json:"block_time"`
+	// RetargetInterval is the number of blocks after which the target is recalculated
+	RetargetInterval int64 `json:"retarget_interval"`
+
+	// GenesisDifficulty is the difficulty of the genesis block
+	GenesisDifficulty uint8 `json:"genesis_difficulty"`
+	// MinimumDifficulty is the lowest difficulty that a retarget can produce
+	MinimumDifficulty uint8 `json:"minimum_difficulty"`
+
+	// BlockReward is the reward in Nubs of the blocks before the first halving
+	BlockReward uint64 `json:"block_reward"`
+	// HalvingInterval is the number of blocks after which the block reward is halved
+	HalvingInterval int64 `json:"halving_interval"`
+	// MaxSupply is the maximum total of block rewards in Nubs. Genesis allocations
+	// are not counted towards it. The block rewards are not capped if it is 0.
+	MaxSupply uint64 `json:"max_supply"`
+	// CoinbaseMaturity is the number of blocks after which the value of a coinbase transaction can be spent.
+	// Coinbase credits can be spent immediately if it is 0.
+	CoinbaseMaturity int64 `json:"coinbase_maturity"`
+
+	// PoA is the configuration of the Proof of Authority engine
+	PoA *PoAConfig `json:"poa,omitempty"`
+	// PoS is the configuration of the Proof of Stake engine
+	PoS *PoSConfig `json:"pos,omitempty"`
+	// Instant is the configuration of the development engine
+	Instant *InstantConfig `json:"instant,omitempty"`
+}
+
+// PoAConfig represents the configurable parameters of the Proof of Authority engine
+type PoAConfig struct {
+	// Period is the minimum number of seconds between blocks
+	Period int64 `json:"period"`
+	// Epoch is the number of blocks after which all pending votes are discarded
+	Epoch int64 `json:"epoch"`
+
+	// Authorities is the initial set of signer addresses
+	Authorities []common.Address `json:"authorities"`
+}
+
+// DefaultChainConfig returns the default ChainConfig for the Essensio Blockchain
+func DefaultChainConfig() *ChainConfig {
+	return &ChainConfig{
+		ChainID:           DefaultChainID,
+		Engine:            EngineProofOfWork,
+		PoWAlgorithm:      PoWSHA256d,
+		BlockTime:         10,
+		RetargetInterval:  20,
+		GenesisDifficulty: BlockDifficulty,
+		MinimumDifficulty: 8,
+		BlockReward:       BlockReward,
+		HalvingInterval:   DefaultHalvingInterval,
+		MaxSupply:         2 * DefaultHalvingInterval * BlockReward,
+		CoinbaseMaturity:  DefaultCoinbaseMaturity,
+	}
+}
+
+// Validate checks that the parameters of the ChainConfig are within their valid ranges.
+// The difficulty parameters are only checked for the Proof of Work engine, which is the only engine that uses them.
+// Returns an error describing the first invalid parameter.
+func (config *ChainConfig) Validate() error {
+	switch {
+	case config.ChainID == 0:
+		return fmt.Errorf("chain id must not be 0")
+	case config.HalvingInterval <= 0:
+		return fmt.Errorf("halving interval must be positive, got %v", config.HalvingInterval)
+	case config.MaxSupply > 0 && config.MaxSupply < config.BlockReward:
+		return fmt.Errorf("max supply %v is below the block reward %v", config.MaxSupply, config.BlockReward)
+	case config.CoinbaseMaturity < 0:
+		return fmt.Errorf("coinbase maturity must not be negative, got %v", config.CoinbaseMaturity)
+	}
+
+	switch config.Engine {
+	case EngineProofOfWork:
+		if _, err := NewPoWAlgorithm(config.PoWAlgorithm); err != nil {
+			return err
+		}
+
+		switch {
+		case config.BlockTime <= 0:
+			return fmt.Errorf("block time must be positive, got %v", config.BlockTime)
+		case config.RetargetInterval <= 0:
+			return fmt.Errorf("retarget interval must be positive, got %v", config.RetargetInterval)
+		case config.MinimumDifficulty == 0 || config.MinimumDifficulty > config.GenesisDifficulty:
+			return fmt.Errorf("minimum difficulty must be between 1 and the genesis difficulty %v, got %v",
+				config.GenesisDifficulty, config.MinimumDifficulty)
+		}
+
+	case EngineProofOfStake:
+		if config.PoS != nil {
+			return config.PoS.Validate()
+		}
+
+	case EngineProofOfAuthority, EngineInstant:
+	default:
+		return fmt.Errorf("unknown consensus engine '%v'", config.Engine)
+	}
+
+	return nil
+}
+
+// PoSConfig represents the configurable parameters of the Proof of Stake engine
+type PoSConfig struct {
+	// Period is the minimum number of seconds between blocks
+	Period int64 `json:"period"`
+	// UnbondingPeriod is the number of blocks after which unbonded stake is released
+	UnbondingPeriod int64 `json:"unbonding_period"`
+	// MinimumStake is the lowest stake, in Nubs, with which an account can propose blocks
+	MinimumStake uint64 `json:"minimum_stake"`
+
+	// Validators is the initial stake of each validator
+	Validators map[common.Address]uint64 `json:"validators"`
+}
+
+// Validate checks that the PoSConfig releases unbonded stake after at least 1 block
+// and that some initial validator has the minimum stake to propose blocks
+func (config *PoSConfig) Validate() error {
+	if config.UnbondingPeriod < 1 {
+		return fmt.Errorf("unbonding period must be at least 1 block, got %v", config.UnbondingPeriod)
+	}
+
+	for _, stake := range config.Validators {
+		if stake >= config.MinimumStake && stake > 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no initial validator has the minimum stake %v", config.MinimumStake)
+}
+
+// InstantConfig represents the configurable parameters of the development engine
+type InstantConfig struct {
+	// Period is the number of seconds between blocks. Blocks are sealed immediately if it is 0.
+	Period int64 `json:"period"`
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// DefaultGenesisTimestamp is the timestamp of the genesis block of the default
+// Essensio chain, which is fixed so that every node produces the same genesis block
+const DefaultGenesisTimestamp = 1672531200
+
+// GenesisAlloc represents the initial balances of accounts, in Nubs, minted in the genesis block
+type GenesisAlloc map[common.Address]uint64
+
+// transactions returns the mint Transactions for the allocations sorted by address for the given chain ID
+func (alloc GenesisAlloc) transactions(chainID uint64) Transactions {
+	addresses := make([]common.Address, 0, len(alloc))
+	for address := range alloc {
+		addresses = append(addresses, address)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i] < addresses[j] })
+
+	txns := make(Transactions, 0, len(addresses))
+	for _, address := range addresses {
+		txns = append(txns, NewMintTransaction(chainID, address, alloc[address]))
+	}
+
+	return txns
+}
+
+// Genesis represents the configuration and the initial state of a chain.
+// Nodes with the same Genesis produce the same genesis block.
+type Genesis struct {
+	// Config is the configuration of the chain
+	Config *ChainConfig `json:"config"`
+
+	// Timestamp is the timestamp of the genesis block
+	Timestamp int64 `json:"timestamp"`
+	// ExtraData is the data in the Extra field of the genesis block header
+	ExtraData common.HexBytes `json:"extra_data,omitempty"`
+	// Coinbase is the address that receives the block reward of the genesis block
+	Coinbase common.Address `json:"coinbase"`
+
+	// Alloc is the initial balances of accounts
+	Alloc GenesisAlloc `json:"alloc,omitempty"`
+}
+
+// DefaultGenesis returns the Genesis of the default Essensio chain
+func DefaultGenesis() *Genesis {
+	return &Genesis{
+		Config:    DefaultChainConfig(),
+		Timestamp: DefaultGenesisTimestamp,
+		Coinbase:  common.MinerAddress(),
+	}
+}
+
+// LoadGenesis reads the Genesis from the JSON file at the given path.
+// Fields that are not set in the file keep their value from DefaultGenesis.
+func LoadGenesis(path string) (*Genesis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("genesis file read failed: %w", err)
+	}
+
+	// Decode the file over the default genesis, rejecting unknown fields
+	genesis := DefaultGenesis()
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(genesis); err != nil {
+		return nil, fmt.Errorf("genesis file decode failed: %w", err)
+	}
+
+	if genesis.Config == nil {
+		return nil, fmt.Errorf("genesis file has no chain config")
+	}
+
+	if err := genesis.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid genesis chain config: %w", err)
+	}
+
+	return genesis, nil
+}
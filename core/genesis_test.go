@@ -0,0 +1,76 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadGenesis(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		valid bool
+	}{
+		{"defaults", `{}`, true},
+		{"allocations", `{"timestamp": 1, "extra_data": "0x0102", "alloc": {"manish": 100, "satoshi": 5}}`, true},
+		{"chain parameters", `{"config": {"chain_id": 7, "engine": "pow", "pow_algorithm": "sha256d", "block_time": 5,
+			"retarget_interval": 10, "genesis_difficulty": 12, "minimum_difficulty": 4, "block_reward": 10,
+			"halving_interval": 100, "max_supply": 0, "coinbase_maturity": 0}}`, true},
+		{"authority engine without difficulty", `{"config": {"engine": "poa", "genesis_difficulty": 0, "minimum_difficulty": 0,
+			"poa": {"period": 5, "authorities": ["0x0000000000000000000000000000000000000001"]}}}`, true},
+		{"stake engine", `{"config": {"engine": "pos", "pos": {"unbonding_period": 1, "minimum_stake": 10, "validators": {"a": 10}}}}`, true},
+		{"invalid json", `{"timestamp": }`, false},
+		{"unknown field", `{"difficulty": 18}`, false},
+		{"mistyped field", `{"timestamp": "now"}`, false},
+		{"no chain config", `{"config": null}`, false},
+		{"zero chain id", `{"config": {"chain_id": 0}}`, false},
+		{"unknown engine", `{"config": {"engine": "pob"}}`, false},
+		{"unknown algorithm", `{"config": {"pow_algorithm": "md5"}}`, false},
+		{"zero block time", `{"config": {"block_time": 0}}`, false},
+		{"zero retarget interval", `{"config": {"retarget_interval": 0}}`, false},
+		{"minimum above genesis difficulty", `{"config": {"genesis_difficulty": 8, "minimum_difficulty": 9}}`, false},
+		{"zero minimum difficulty", `{"config": {"minimum_difficulty": 0}}`, false},
+		{"zero halving interval", `{"config": {"halving_interval": 0}}`, false},
+		{"max supply below the reward", `{"config": {"block_reward": 10, "max_supply": 9}}`, false},
+		{"negative maturity", `{"config": {"coinbase_maturity": -1}}`, false},
+		{"no unbonding period", `{"config": {"engine": "pos", "pos": {"unbonding_period": 0, "minimum_stake": 10, "validators": {"a": 10}}}}`, false},
+		{"no validator with the minimum stake", `{"config": {"engine": "pos", "pos": {"unbonding_period": 1, "minimum_stake": 10, "validators": {"a": 9}}}}`, false},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "genesis.json")
+		if err := os.WriteFile(path, []byte(test.data), 0o600); err != nil {
+			t.Fatalf("failed to write genesis file: %v", err)
+		}
+
+		genesis, err := LoadGenesis(path)
+		if test.valid != (err == nil) {
+			t.Errorf("%v: got error %v, want valid %v", test.name, err, test.valid)
+			continue
+		}
+
+		if !test.valid {
+			continue
+		}
+
+		// The genesis block must be the same every time it is generated
+		first, err := GenesisBlock(genesis)
+		if err != nil {
+			t.Fatalf("%v: failed to generate genesis block: %v", test.name, err)
+		}
+
+		second, err := GenesisBlock(genesis)
+		if err != nil {
+			t.Fatalf("%v: failed to generate genesis block: %v", test.name, err)
+		}
+
+		if first.Hash() != second.Hash() {
+			t.Errorf("%v: genesis block hashes to %v and %v", test.name, first.Hash().Hex(), second.Hash().Hex())
+		}
+	}
+
+	if _, err := LoadGenesis(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("expected error for a missing genesis file")
+	}
+}
@@ -1,12 +1,20 @@
 package core
 
 import (
+	"encoding/binary"
 	"math/big"
 	"time"
 
 	"github.com/manishmeganathan/essensio/common"
 )
 
+const (
+	// HeaderNonceOffset is the offset of the big-endian Nonce in the encoded BlockHeader
+	HeaderNonceOffset = 3*common.HashLength + 8 + common.HashLength
+	// headerFixedLength is the length of the fixed size fields of the encoded BlockHeader
+	headerFixedLength = HeaderNonceOffset + 8
+)
+
 // BlockHeader is a struct that contains all the fields
 // of the block that are relevant to its cryptographic integrity.
 // The Block Hash is the hash of the Block Header.
@@ -15,6 +23,8 @@ type BlockHeader struct {
 	Priori common.Hash
 	// Hash of the all the data in the block
 	Summary common.Hash
+	// Root of the account state trie after the block is applied
+	StateRoot common.Hash
 	// Timestamp at the time of block creation
 	Timestamp int64
 
@@ -22,17 +32,100 @@ type BlockHeader struct {
 	Target *big.Int
 	// Proof of Work Nonce
 	Nonce int64
+
+	// Consensus engine specific data, such as signer votes
+	Extra []byte
+	// Signature of the block sealer for signature based consensus engines.
+	// It is not included in the SealHash of the header.
+	Seal []byte
 }
 
-// NewBlockHeader returns a new BlockHeader for a given priori and summary hash
+// NewBlockHeader returns a new BlockHeader for a given priori and summary hash.
+// The Target and Nonce of the header are set by the consensus engine
+// and the StateRoot is set once the transactions of the block are applied.
 func NewBlockHeader(priori, summary common.Hash) BlockHeader {
 	return BlockHeader{
-		priori,
-		summary,
-		time.Now().Unix(),
-		GenerateTarget(),
-		0,
+		Priori:    priori,
+		Summary:   summary,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// Copy returns a deep copy of the BlockHeader that does not share its Target, Extra or Seal
+func (header *BlockHeader) Copy() *BlockHeader {
+	cpy := *header
+	if header.Target != nil {
+		cpy.Target = new(big.Int).Set(header.Target)
+	}
+
+	if header.Extra != nil {
+		cpy.Extra = append([]byte{}, header.Extra...)
 	}
+
+	if header.Seal != nil {
+		cpy.Seal = append([]byte{}, header.Seal...)
+	}
+
+	return &cpy
+}
+
+// Hash returns the hash of the BlockHeader's encoded representation.
+func (header *BlockHeader) Hash() common.Hash {
+	return common.Hash256(header.encode(true))
+}
+
+// SealHash returns the hash of the BlockHeader's encoded representation without the Seal.
+// This is the hash that is signed by signature based consensus engines.
+func (header *BlockHeader) SealHash() common.Hash {
+	return common.Hash256(header.encode(false))
+}
+
+// WorkData returns the encoded BlockHeader that is hashed by the Proof of Work.
+// External miners hash this data with the nonce set at HeaderNonceOffset.
+func (header *BlockHeader) WorkData() []byte {
+	return header.encode(true)
+}
+
+// encode returns the binary encoding of the BlockHeader that is used for hashing.
+// The fields are laid out in order as Priori, Summary, StateRoot, Timestamp, Target and Nonce,
+// with integers in big-endian order and the Target padded to 32 bytes.
+// The Nonce is always the 8 bytes at HeaderNonceOffset. The fixed size fields are
+// followed by the length prefixed Extra and, if withSeal is set, the length prefixed Seal.
+func (header *BlockHeader) encode(withSeal bool) []byte {
+	data := make([]byte, headerFixedLength, headerFixedLength+8+len(header.Extra)+len(header.Seal))
+
+	copy(data[0:], header.Priori[:])
+	copy(data[common.HashLength:], header.Summary[:])
+	copy(data[2*common.HashLength:], header.StateRoot[:])
+	binary.BigEndian.PutUint64(data[3*common.HashLength:], uint64(header.Timestamp))
+
+	target := header.targetHash()
+	copy(data[3*common.HashLength+8:], target[:])
+	binary.BigEndian.PutUint64(data[HeaderNonceOffset:], uint64(header.Nonce))
+
+	data = appendBytes(data, header.Extra)
+	if withSeal {
+		data = appendBytes(data, header.Seal)
+	}
+
+	return data
+}
+
+// appendBytes appends the given bytes to data prefixed by their length as a big-endian uint32
+func appendBytes(data, b []byte) []byte {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(b)))
+
+	return append(append(data, length[:]...), b...)
+}
+
+// targetHash returns the Target of the BlockHeader as a 32 byte big-endian value
+func (header *BlockHeader) targetHash() common.Hash {
+	if header.Target == nil {
+		return common.NullHash()
+	}
+
+	return common.BytesToHash(header.Target.Bytes())
 }
 
 // Serialize implements the common.Serializable interface for BlockHeader.
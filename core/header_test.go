@@ -0,0 +1,23 @@
+package core
+
+import "testing"
+
+func TestBlockHeaderCopy(t *testing.T) {
+	header := testHeader()
+	header.Seal = []byte("seal")
+
+	cpy := header.Copy()
+	if cpy.Hash() != header.Hash() {
+		t.Fatalf("copy hashes to %v, want %v", cpy.Hash().Hex(), header.Hash().Hex())
+	}
+
+	// Modifying the copy must not modify the original
+	hash := header.Hash()
+	cpy.Target.SetInt64(1)
+	cpy.Extra[0]++
+	cpy.Seal[0]++
+
+	if got := header.Hash(); got != hash {
+		t.Errorf("original hashes to %v after modifying the copy, want %v", got.Hex(), hash.Hex())
+	}
+}
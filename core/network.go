@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// NetworkMainnet is the name of the main Essensio network
+	NetworkMainnet = "mainnet"
+	// NetworkTestnet is the name of the public test network with a low difficulty
+	NetworkTestnet = "testnet"
+	// NetworkDevnet is the name of the development network that seals blocks without any work
+	NetworkDevnet = "devnet"
+)
+
+// Network represents a named network with its own genesis and default settings.
+// The data of each network is stored in a separate directory named after it.
+type Network struct {
+	// Name is the name of the network
+	Name string
+	// RPCPort is the default port of the JSON-RPC server
+	RPCPort int
+	// Genesis returns a new copy of the Genesis of the network
+	Genesis func() *Genesis
+}
+
+// networks is the collection of the named networks indexed by their name
+var networks = map[string]*Network{
+	NetworkMainnet: {
+		Name:    NetworkMainnet,
+		RPCPort: 8080,
+		Genesis: DefaultGenesis,
+	},
+	NetworkTestnet: {
+		Name:    NetworkTestnet,
+		RPCPort: 8081,
+		Genesis: func() *Genesis {
+			genesis := DefaultGenesis()
+			genesis.Timestamp = 1672617600
+			genesis.Config.ChainID = 2
+			genesis.Config.GenesisDifficulty = 14
+			genesis.Config.CoinbaseMaturity = 10
+
+			return genesis
+		},
+	},
+	NetworkDevnet: {
+		Name:    NetworkDevnet,
+		RPCPort: 8082,
+		Genesis: func() *Genesis {
+			genesis := DefaultGenesis()
+			genesis.Timestamp = 1672704000
+			genesis.Config.ChainID = 1337
+			genesis.Config.Engine = EngineInstant
+			genesis.Config.Instant = &InstantConfig{Period: 5}
+			genesis.Config.CoinbaseMaturity = 0
+
+			return genesis
+		},
+	},
+}
+
+// GetNetwork returns the Network with the given name.
+// Returns an error if there is no network with the name.
+func GetNetwork(name string) (*Network, error) {
+	network, ok := networks[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown network '%v'", name)
+	}
+
+	return network, nil
+}
+
+// NetworkNames returns the names of all the networks in ascending order
+func NetworkNames() []string {
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
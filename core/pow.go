@@ -1,79 +1,272 @@
 package core
 
 import (
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
-	"log"
 	"math"
 	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/manishmeganathan/essensio/common"
 )
 
 const (
+	// powCheckInterval is the number of nonces after which mining checks for cancellation
+	powCheckInterval = 1 << 10
+	// powReportInterval is the number of nonces after which mining progress is printed
+	powReportInterval = 1 << 16
+
 	// BlockDifficulty represents the number of bits that need to be 0 for the Proof Of Work Algorithm.
-	// Currently static, but can eventually be adjusted based on the total hash rate of the network.
+	// This is the difficulty of the genesis block, it is adjusted every retarget interval after that.
 	BlockDifficulty uint8 = 18
 
-	// BlockReward represents the standard reward for mining a Block
+	// retargetClamp is the maximum factor by which the target can change in a single retarget
+	retargetClamp = 4
+
+	// BlockReward represents the default reward for mining a Block before the first halving.
 	// The default block reward is 5 Essences or 1 Quintessence
 	BlockReward = common.Quintessence
 )
 
-// GenerateTarget returns a big.Int with the target hash value for the current difficulty
-func GenerateTarget() *big.Int {
+var (
+	// ErrMiningCancelled is returned when the Proof of Work is stopped before a nonce is found
+	ErrMiningCancelled = errors.New("mining cancelled")
+	// ErrNonceExhausted is returned when no valid nonce exists for the header
+	ErrNonceExhausted = errors.New("nonce space exhausted")
+)
+
+// HeaderReader is an interface for types that can
+// retrieve the BlockHeader of the canonical chain at some height.
+type HeaderReader interface {
+	// GetHeaderByHeight returns the BlockHeader at the given height
+	GetHeaderByHeight(int64) (*BlockHeader, error)
+}
+
+// GenerateTarget returns a big.Int with the target hash value for the given difficulty
+func GenerateTarget(difficulty uint8) *big.Int {
 	// Generate a new big Integer and left shift to match difficulty
 	target := big.NewInt(1)
-	target.Lsh(target, 256-uint(BlockDifficulty))
+	target.Lsh(target, 256-uint(difficulty))
 
 	return target
 }
 
-// Mint is the Proof of Work routine that generates a nonce
-// that is valid for the Target difficulty of the header.
-func (header *BlockHeader) Mint() common.Hash {
-	var hash common.Hash
+// CalcNextTarget returns the expected target for a Block at the given height.
+// The target is recalculated every RetargetInterval blocks based on the time taken
+// to produce the blocks of the last interval compared to the configured BlockTime.
+// The target is carried over from the parent block for all other heights.
+func CalcNextTarget(config *ChainConfig, chain HeaderReader, height int64) (*big.Int, error) {
+	// Genesis block uses the genesis difficulty
+	if height == 0 {
+		return GenerateTarget(config.GenesisDifficulty), nil
+	}
+
+	// Get the parent header
+	parent, err := chain.GetHeaderByHeight(height - 1)
+	if err != nil {
+		return nil, fmt.Errorf("parent header retrieve failed: %w", err)
+	}
+
+	// Carry over the parent target if not at a retarget height
+	if height%config.RetargetInterval != 0 {
+		return new(big.Int).Set(parent.Target), nil
+	}
 
-	// Reset Nonce
-	header.Nonce = 0
+	// Get the first header of the retarget interval
+	first, err := chain.GetHeaderByHeight(height - config.RetargetInterval)
+	if err != nil {
+		return nil, fmt.Errorf("interval header retrieve failed: %w", err)
+	}
 
-	for header.Nonce < math.MaxInt64 {
-		// Serialize the Header
-		data, err := header.Serialize()
-		if err != nil {
-			log.Fatalln("header serialization failed during PoW:", err)
+	// Calculate the actual and expected timespan of the interval
+	expected := config.RetargetInterval * config.BlockTime
+	actual := parent.Timestamp - first.Timestamp
+
+	// Clamp the actual timespan to limit the change in target
+	if actual < expected/retargetClamp {
+		actual = expected / retargetClamp
+	}
+	if actual > expected*retargetClamp {
+		actual = expected * retargetClamp
+	}
+
+	// Scale the parent target by the ratio of the actual and expected timespan
+	target := new(big.Int).Mul(parent.Target, big.NewInt(actual))
+	target.Div(target, big.NewInt(expected))
+
+	// Limit the target to the minimum difficulty
+	if limit := GenerateTarget(config.MinimumDifficulty); target.Cmp(limit) > 0 {
+		target = limit
+	}
+
+	return target, nil
+}
+
+// Mint is the Proof of Work routine that generates a nonce that is valid for the Target
+// difficulty of the header with the given algorithm. Returns the hash of the mined header.
+// Returns ErrMiningCancelled if the context is cancelled before a nonce is found.
+func (header *BlockHeader) Mint(ctx context.Context, algorithm PoWAlgorithm) (common.Hash, error) {
+	// Pre-encode the Header for the hashing loop
+	work := newPowWork(header, algorithm)
+
+	for nonce := int64(0); nonce < math.MaxInt64; nonce++ {
+		// Check periodically if the mining has been cancelled
+		if nonce%powCheckInterval == 0 && ctx.Err() != nil {
+			fmt.Println()
+			return common.NullHash(), fmt.Errorf("%w: %v", ErrMiningCancelled, ctx.Err())
 		}
 
-		// Hash the Header data
-		hash = common.Hash256(data)
+		// Hash the Header data with the nonce
+		hash := work.hash(nonce)
 
-		// Print the hash mining process
-		fmt.Printf("\rMining Block [%v]: %v", header.Nonce, hash.Hex())
+		// Print the hash mining process, throttled to every powReportInterval nonces
+		if nonce%powReportInterval == 0 {
+			fmt.Printf("\rMining Block [%v]: %v", nonce, hash.Hex())
+		}
 
 		// Compare the hash with target
-		if hash.Big().Cmp(header.Target) == -1 {
-			break // Block Mined!
-		} else {
-			// Increment Nonce & Repeat
-			header.Nonce++
+		if work.valid(hash) {
+			header.Nonce = nonce
+
+			fmt.Printf("\rMining Block [%v]: %v\n", nonce, hash.Hex())
+			return header.Hash(), nil // Block Mined!
 		}
 	}
 
 	fmt.Println()
-	return hash
+	return common.NullHash(), ErrNonceExhausted
 }
 
-// Validate is the Proof of Work validation routine.
-// Returns a boolean indicating if the hash of the block is valid for its target.
-func (header *BlockHeader) Validate() bool {
-	// Serialize the Header
-	data, err := header.Serialize()
-	if err != nil {
-		log.Fatalln("header serialization failed during PoW:", err)
+// MiningStats represents the statistics of a Proof of Work run
+type MiningStats struct {
+	// Represents the total number of hashes computed across all workers
+	Hashes uint64
+	// Represents the time taken to find a valid nonce
+	Duration time.Duration
+}
+
+// HashRate returns the aggregate number of hashes computed per second
+func (stats MiningStats) HashRate() float64 {
+	if stats.Duration <= 0 {
+		return 0
+	}
+
+	return float64(stats.Hashes) / stats.Duration.Seconds()
+}
+
+// MintParallel is the multi-threaded Proof of Work routine that generates a nonce
+// that is valid for the Target difficulty of the header with the given algorithm. The nonce space is split
+// across the given number of worker goroutines, each starting at its worker index
+// and stepping by the number of workers. All workers stop once any of them finds a valid nonce
+// or the context is cancelled, in which case ErrMiningCancelled is returned.
+func (header *BlockHeader) MintParallel(ctx context.Context, algorithm PoWAlgorithm, threads int) (common.Hash, MiningStats, error) {
+	if threads < 1 {
+		threads = 1
+	}
+
+	var (
+		wg     sync.WaitGroup
+		found  int32
+		hashes uint64
+		nonce  int64
+	)
+
+	start := time.Now()
+
+	for worker := 0; worker < threads; worker++ {
+		wg.Add(1)
+
+		go func(worker int64) {
+			defer wg.Done()
+
+			// Pre-encode the header so that each worker has its own nonce bytes
+			work := newPowWork(header, algorithm)
+			var count uint64
+
+			for attempt := worker; attempt >= 0; attempt += int64(threads) {
+				// Check periodically if another worker has found a nonce or mining is cancelled
+				if count%powCheckInterval == 0 && (atomic.LoadInt32(&found) == 1 || ctx.Err() != nil) {
+					break
+				}
+
+				// Hash the Header and compare it with the target
+				hash := work.hash(attempt)
+				count++
+
+				if work.valid(hash) {
+					// Block Mined! Only the first worker to find a nonce sets the result
+					if atomic.CompareAndSwapInt32(&found, 0, 1) {
+						nonce = attempt
+					}
+
+					break
+				}
+
+				// Stop before the nonce overflows
+				if attempt > math.MaxInt64-int64(threads) {
+					break
+				}
+			}
+
+			atomic.AddUint64(&hashes, count)
+		}(int64(worker))
 	}
 
-	// Hash the Header data
-	hash := common.Hash256(data)
+	// Wait for all workers to stop
+	wg.Wait()
+
+	stats := MiningStats{hashes, time.Since(start)}
 
+	// Check if any worker found a nonce
+	if found == 0 {
+		if ctx.Err() != nil {
+			return common.NullHash(), stats, fmt.Errorf("%w: %v", ErrMiningCancelled, ctx.Err())
+		}
+
+		return common.NullHash(), stats, ErrNonceExhausted
+	}
+
+	header.Nonce = nonce
+	return header.Hash(), stats, nil
+}
+
+// Validate is the Proof of Work validation routine.
+// Returns a boolean indicating if the Proof of Work hash of the header
+// generated with the given algorithm is valid for its target.
+func (header *BlockHeader) Validate(algorithm PoWAlgorithm) bool {
 	// Compare hash with target
-	return hash.Big().Cmp(header.Target) == -1
+	work := newPowWork(header, algorithm)
+	return work.valid(work.hash(header.Nonce))
+}
+
+// powWork is a BlockHeader that has been pre-encoded for the Proof of Work hashing loop.
+// Only the nonce bytes at HeaderNonceOffset are rewritten for each attempt,
+// avoiding the cost of serializing the entire header for every nonce.
+type powWork struct {
+	// Represents the encoded header
+	data []byte
+	// Represents the target as a big-endian 32 byte value
+	target common.Hash
+	// Represents the hashing algorithm of the Proof of Work
+	algorithm PoWAlgorithm
+}
+
+// newPowWork returns a new powWork for the given BlockHeader and algorithm
+func newPowWork(header *BlockHeader, algorithm PoWAlgorithm) *powWork {
+	return &powWork{header.encode(true), header.targetHash(), algorithm}
+}
+
+// hash sets the given nonce into the encoded header and returns its Proof of Work hash
+func (work *powWork) hash(nonce int64) common.Hash {
+	binary.BigEndian.PutUint64(work.data[HeaderNonceOffset:], uint64(nonce))
+	return work.algorithm.Hash(work.data)
+}
+
+// valid returns whether the given Proof of Work hash satisfies the target
+func (work *powWork) valid(hash common.Hash) bool {
+	return work.algorithm.Valid(hash, work.target)
 }
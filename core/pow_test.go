@@ -0,0 +1,191 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+// testHeader returns a BlockHeader with every field set for the Proof of Work tests
+func testHeader() *BlockHeader {
+	return &BlockHeader{
+		Priori:    common.Hash256([]byte("priori")),
+		Summary:   common.Hash256([]byte("summary")),
+		StateRoot: common.Hash256([]byte("state")),
+		Timestamp: DefaultGenesisTimestamp,
+		Target:    GenerateTarget(BlockDifficulty),
+		Extra:     []byte("extra"),
+	}
+}
+
+func TestPowWorkHash(t *testing.T) {
+	header := testHeader()
+	work := newPowWork(header, SHA256d{})
+
+	for _, nonce := range []int64{0, 1, 255, 256, 1 << 32, -1, 1<<63 - 1} {
+		header.Nonce = nonce
+
+		if got, want := work.hash(nonce), header.Hash(); got != want {
+			t.Errorf("nonce %v: pre-encoded hash %v, header hash %v", nonce, got.Hex(), want.Hex())
+		}
+	}
+}
+
+func TestMintParallel(t *testing.T) {
+	tests := []struct {
+		threads    int
+		difficulty uint8
+		cancelled  bool
+		want       error
+	}{
+		{1, 10, false, nil},
+		{2, 10, false, nil},
+		{4, 10, false, nil},
+		{0, 10, false, nil},
+		{4, 64, true, ErrMiningCancelled},
+		{1, 64, true, ErrMiningCancelled},
+	}
+
+	for _, test := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		if test.cancelled {
+			cancel()
+		}
+
+		header := testHeader()
+		header.Target = GenerateTarget(test.difficulty)
+
+		hash, stats, err := header.MintParallel(ctx, SHA256d{}, test.threads)
+		cancel()
+
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v threads: got error %v, want %v", test.threads, err, test.want)
+			continue
+		}
+
+		if test.want != nil {
+			continue
+		}
+
+		// The mined nonce must be set in the header and valid for its target
+		if !header.Validate(SHA256d{}) {
+			t.Errorf("%v threads: mined nonce %v is not valid", test.threads, header.Nonce)
+		}
+
+		if hash != header.Hash() {
+			t.Errorf("%v threads: returned hash %v, header hash %v", test.threads, hash.Hex(), header.Hash().Hex())
+		}
+
+		if stats.Hashes == 0 {
+			t.Errorf("%v threads: no hashes counted", test.threads)
+		}
+	}
+}
+
+func BenchmarkHashGob(b *testing.B) {
+	header := testHeader()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for nonce := 0; nonce < b.N; nonce++ {
+		header.Nonce = int64(nonce)
+
+		data, err := common.GobEncode(header)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		common.Hash256(data)
+	}
+}
+
+func BenchmarkHashPreEncoded(b *testing.B) {
+	work := newPowWork(testHeader(), SHA256d{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for nonce := 0; nonce < b.N; nonce++ {
+		work.hash(int64(nonce))
+	}
+}
+
+// testHeaders is a HeaderReader for a list of headers indexed by height
+type testHeaders []*BlockHeader
+
+// GetHeaderByHeight implements the HeaderReader interface for testHeaders
+func (headers testHeaders) GetHeaderByHeight(height int64) (*BlockHeader, error) {
+	if height < 0 || height >= int64(len(headers)) {
+		return nil, fmt.Errorf("block height %v out of range", height)
+	}
+
+	return headers[height], nil
+}
+
+// newTestHeaders returns testHeaders for the given number of blocks with the given target.
+// The blocks are spaced by the given interval, except for the last one which is produced after the given delay.
+func newTestHeaders(blocks int, target *big.Int, interval, delay int64) testHeaders {
+	headers := make(testHeaders, blocks)
+	for height := range headers {
+		headers[height] = &BlockHeader{Timestamp: DefaultGenesisTimestamp + int64(height)*interval, Target: target}
+	}
+
+	headers[blocks-1].Timestamp = headers[blocks-2].Timestamp + delay
+	return headers
+}
+
+func TestCalcNextTarget(t *testing.T) {
+	config := DefaultChainConfig()
+	config.BlockTime = 10
+	config.RetargetInterval = 4
+
+	genesis := GenerateTarget(config.GenesisDifficulty)
+	limit := GenerateTarget(config.MinimumDifficulty)
+
+	// scale returns the target multiplied by num and divided by den
+	scale := func(target *big.Int, num, den int64) *big.Int {
+		scaled := new(big.Int).Mul(target, big.NewInt(num))
+		return scaled.Div(scaled, big.NewInt(den))
+	}
+
+	tests := []struct {
+		name    string
+		headers testHeaders
+		height  int64
+		want    *big.Int
+	}{
+		{"genesis", nil, 0, genesis},
+		{"carried over", newTestHeaders(3, genesis, 10, 10), 3, genesis},
+		{"carried over after slow block", newTestHeaders(6, genesis, 10, 1000), 6, genesis},
+		{"on time", newTestHeaders(4, genesis, 10, 20), 4, genesis},
+		{"fast blocks", newTestHeaders(4, genesis, 5, 5), 4, scale(genesis, 15, 40)},
+		{"slow blocks", newTestHeaders(4, genesis, 20, 20), 4, scale(genesis, 60, 40)},
+		{"clamped fast blocks", newTestHeaders(4, genesis, 0, 1), 4, scale(genesis, 1, retargetClamp)},
+		{"clamped slow blocks", newTestHeaders(4, genesis, 1000, 1000), 4, scale(genesis, retargetClamp, 1)},
+		{"minimum difficulty", newTestHeaders(4, scale(limit, 1, 2), 1000, 1000), 4, limit},
+	}
+
+	for _, test := range tests {
+		got, err := CalcNextTarget(config, test.headers, test.height)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		if got.Cmp(test.want) != 0 {
+			t.Errorf("%v: target %x, want %x", test.name, got, test.want)
+		}
+	}
+
+	// The target cannot be calculated without the parent and the first header of the interval
+	for _, height := range []int64{3, 4} {
+		if _, err := CalcNextTarget(config, newTestHeaders(2, genesis, 10, 10), height); err == nil {
+			t.Errorf("height %v: expected error for missing headers", height)
+		}
+	}
+}
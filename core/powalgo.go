@@ -0,0 +1,84 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+const (
+	// PoWSHA256d is the name of the double SHA-256 Proof of Work algorithm
+	PoWSHA256d = "sha256d"
+	// PoWScrypt is the name of the memory-hard scrypt Proof of Work algorithm
+	PoWScrypt = "scrypt"
+
+	// ScryptBlockDifficulty is the genesis difficulty for the scrypt Proof of Work algorithm.
+	// It is lower than BlockDifficulty because each scrypt hash is far more expensive.
+	ScryptBlockDifficulty uint8 = 10
+)
+
+// PoWAlgorithm is an interface for the hashing algorithms of the Proof of Work.
+// The algorithm only determines the Proof of Work hash, the hash of a BlockHeader is always its Hash.
+type PoWAlgorithm interface {
+	// Hash returns the Proof of Work hash of the encoded header
+	Hash(data []byte) common.Hash
+	// Valid returns whether the Proof of Work hash satisfies the target,
+	// given as a 32 byte big-endian value
+	Valid(hash, target common.Hash) bool
+}
+
+// NewPoWAlgorithm returns the PoWAlgorithm for the given name.
+// An empty name returns the default SHA256d algorithm.
+func NewPoWAlgorithm(name string) (PoWAlgorithm, error) {
+	switch name {
+	case "", PoWSHA256d:
+		return SHA256d{}, nil
+	case PoWScrypt:
+		return Scrypt{}, nil
+	default:
+		return nil, fmt.Errorf("unknown proof of work algorithm '%v'", name)
+	}
+}
+
+// SHA256d is the double SHA-256 Proof of Work algorithm.
+// It implements the PoWAlgorithm interface.
+type SHA256d struct{}
+
+// Hash implements the PoWAlgorithm interface for SHA256d.
+// Returns the common.Hash256 of the data.
+func (SHA256d) Hash(data []byte) common.Hash {
+	return common.Hash256(data)
+}
+
+// Valid implements the PoWAlgorithm interface for SHA256d.
+// The hash is read as a big-endian value, so a byte-wise
+// comparison with the target is equivalent to a numeric one.
+func (SHA256d) Valid(hash, target common.Hash) bool {
+	return bytes.Compare(hash[:], target[:]) < 0
+}
+
+// Scrypt is the memory-hard scrypt Proof of Work algorithm.
+// It requires 128 KiB of memory per hash, which reduces the advantage of specialized hardware.
+// It implements the PoWAlgorithm interface.
+type Scrypt struct{}
+
+// Hash implements the PoWAlgorithm interface for Scrypt.
+// Returns the common.ScryptHash of the data.
+func (Scrypt) Hash(data []byte) common.Hash {
+	return common.ScryptHash(data)
+}
+
+// Valid implements the PoWAlgorithm interface for Scrypt.
+// The scrypt output is read as a little-endian value, as done by
+// other scrypt based chains, before comparing it with the target.
+func (Scrypt) Valid(hash, target common.Hash) bool {
+	for i := 0; i < common.HashLength; i++ {
+		h, t := hash[common.HashLength-1-i], target[i]
+		if h != t {
+			return h < t
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"math"
+	"math/big"
+)
+
+const (
+	// DefaultHalvingInterval is the default number of blocks after which the block reward is halved
+	DefaultHalvingInterval = 210000
+	// DefaultCoinbaseMaturity is the default number of blocks after which a block reward can be spent
+	DefaultCoinbaseMaturity = 100
+)
+
+// BlockRewardAt returns the reward in Nubs for the Block at the given height.
+// The reward starts at BlockReward and is halved every HalvingInterval blocks,
+// until the block rewards of the chain reach MaxSupply, after which it is 0.
+func (config *ChainConfig) BlockRewardAt(height int64) uint64 {
+	if height < 0 {
+		return 0
+	}
+
+	return config.Emission(height+1) - config.Emission(height)
+}
+
+// Emission returns the total reward in Nubs of the given number of
+// blocks from the genesis, which is capped at MaxSupply if it is set.
+// Genesis allocations and transaction fees are not part of the emission.
+func (config *ChainConfig) Emission(blocks int64) uint64 {
+	total := new(big.Int)
+
+	if blocks > 0 {
+		if config.HalvingInterval <= 0 {
+			// The reward is never halved
+			total.Mul(new(big.Int).SetUint64(config.BlockReward), big.NewInt(blocks))
+		} else {
+			// Accumulate the reward of each halving epoch until the reward is 0
+			for epoch, start := uint(0), int64(0); epoch < 64 && start < blocks; epoch, start = epoch+1, start+config.HalvingInterval {
+				count := config.HalvingInterval
+				if blocks-start < count {
+					count = blocks - start
+				}
+
+				reward := new(big.Int).SetUint64(config.BlockReward >> epoch)
+				total.Add(total, reward.Mul(reward, big.NewInt(count)))
+			}
+		}
+	}
+
+	// Cap the emission at the maximum supply
+	if config.MaxSupply > 0 && total.Cmp(new(big.Int).SetUint64(config.MaxSupply)) > 0 {
+		return config.MaxSupply
+	}
+
+	if !total.IsUint64() {
+		return math.MaxUint64
+	}
+
+	return total.Uint64()
+}
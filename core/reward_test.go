@@ -0,0 +1,95 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// testRewardConfig returns a ChainConfig with a block reward of 100 that
+// is halved every 10 blocks and the given maximum supply
+func testRewardConfig(supply uint64) *ChainConfig {
+	config := DefaultChainConfig()
+	config.BlockReward = 100
+	config.HalvingInterval = 10
+	config.MaxSupply = supply
+
+	return config
+}
+
+func TestBlockRewardAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *ChainConfig
+		height int64
+		want   uint64
+	}{
+		{"before genesis", testRewardConfig(0), -1, 0},
+		{"genesis", testRewardConfig(0), 0, 100},
+		{"before the first halving", testRewardConfig(0), 9, 100},
+		{"first halving", testRewardConfig(0), 10, 50},
+		{"second halving", testRewardConfig(0), 20, 25},
+		{"odd reward halving", testRewardConfig(0), 30, 12},
+		{"last halving with a reward", testRewardConfig(0), 69, 1},
+		{"reward halved to 0", testRewardConfig(0), 70, 0},
+		{"after all halvings", testRewardConfig(0), 10 * 64, 0},
+		{"below the supply cap", testRewardConfig(1520), 19, 50},
+		{"partial reward at the supply cap", testRewardConfig(1520), 20, 20},
+		{"supply cap reached", testRewardConfig(1520), 21, 0},
+		{"supply cap at the genesis", testRewardConfig(100), 1, 0},
+	}
+
+	for _, test := range tests {
+		if got := test.config.BlockRewardAt(test.height); got != test.want {
+			t.Errorf("%v: reward at %v is %v, want %v", test.name, test.height, got, test.want)
+		}
+	}
+}
+
+func TestEmission(t *testing.T) {
+	unhalved := testRewardConfig(0)
+	unhalved.HalvingInterval = 0
+
+	overflow := testRewardConfig(0)
+	overflow.BlockReward = math.MaxUint64
+	overflow.HalvingInterval = 0
+
+	tests := []struct {
+		name   string
+		config *ChainConfig
+		blocks int64
+		want   uint64
+	}{
+		{"no blocks", testRewardConfig(0), 0, 0},
+		{"negative blocks", testRewardConfig(0), -5, 0},
+		{"first epoch", testRewardConfig(0), 10, 1000},
+		{"within the second epoch", testRewardConfig(0), 15, 1250},
+		{"all epochs", testRewardConfig(0), 10 * 64, 1970},
+		{"beyond all epochs", testRewardConfig(0), math.MaxInt64, 1970},
+		{"capped", testRewardConfig(1520), 30, 1520},
+		{"below the cap", testRewardConfig(1520), 15, 1250},
+		{"never halved", unhalved, 1000, 100000},
+		{"overflow", overflow, 2, math.MaxUint64},
+	}
+
+	for _, test := range tests {
+		if got := test.config.Emission(test.blocks); got != test.want {
+			t.Errorf("%v: emission of %v blocks is %v, want %v", test.name, test.blocks, got, test.want)
+		}
+	}
+
+	// The emission must be the sum of the block rewards and never exceed the supply cap
+	for _, config := range []*ChainConfig{testRewardConfig(0), testRewardConfig(1520), unhalved} {
+		var total uint64
+		for height := int64(0); height < 800; height++ {
+			total += config.BlockRewardAt(height)
+
+			if emission := config.Emission(height + 1); emission != total {
+				t.Fatalf("emission of %v blocks is %v, want the sum of the rewards %v", height+1, emission, total)
+			}
+
+			if config.MaxSupply > 0 && total > config.MaxSupply {
+				t.Fatalf("emission of %v blocks is %v, above the supply cap %v", height+1, total, config.MaxSupply)
+			}
+		}
+	}
+}
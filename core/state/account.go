@@ -0,0 +1,116 @@
+package state
+
+import "github.com/manishmeganathan/essensio/common"
+
+// Account represents the state of an address on the chain
+type Account struct {
+	// Represents the balance of the account in Nubs
+	Balance uint64
+	// Represents the nonce expected for the next transaction sent by the account
+	Nonce uint64
+	// Represents the coinbase credits in the balance that were not spendable
+	// when the account was last modified, ordered by their release height
+	Immature []ImmatureCredit
+
+	// Represents the stake in Nubs bonded by the account, which is held in the balance of the common.StakingAddress.
+	// The Stake of the common.StakingAddress itself is the total stake of the accounts with the minimum stake.
+	Stake uint64
+	// Represents the stake unbonded by the account that is not
+	// released back to it yet, ordered by their release height
+	Unbonding []UnbondingStake
+}
+
+// ImmatureCredit represents a coinbase credit that is included in the balance
+// of an Account but cannot be spent before the Block at its release height
+type ImmatureCredit struct {
+	// Represents the credited amount in Nubs
+	Amount uint64
+	// Represents the height of the first Block in which the amount can be spent
+	Release int64
+}
+
+// UnbondingStake represents stake unbonded by an Account that is released
+// back to it by the Proof of Stake engine in the Block at its release height
+type UnbondingStake struct {
+	// Represents the unbonded amount in Nubs
+	Amount uint64
+	// Represents the height of the Block that releases the amount
+	Release int64
+}
+
+// Empty returns whether the Account has no balance or stake and has never sent a transaction
+func (account *Account) Empty() bool {
+	return account.Balance == 0 && account.Nonce == 0 && account.Stake == 0 && len(account.Unbonding) == 0
+}
+
+// Spendable returns the balance of the Account that can be spent in the Block at the
+// given height, which excludes the coinbase credits released after that height
+func (account *Account) Spendable(height int64) uint64 {
+	spendable := account.Balance
+	for _, credit := range account.Immature {
+		if credit.Release > height {
+			spendable -= credit.Amount
+		}
+	}
+
+	return spendable
+}
+
+// mature removes the coinbase credits that are spendable in the Block at the given height
+func (account *Account) mature(height int64) {
+	var immature []ImmatureCredit
+	for _, credit := range account.Immature {
+		if credit.Release > height {
+			immature = append(immature, credit)
+		}
+	}
+
+	account.Immature = immature
+}
+
+// released returns the index of the unbonding stake of the given amount that is
+// released in the Block at the given height. Returns -1 if there is no such stake.
+func (account *Account) released(amount uint64, height int64) int {
+	for index, unbond := range account.Unbonding {
+		if unbond.Amount == amount && unbond.Release <= height {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// copy returns a copy of the Account that does not share its coinbase credits or unbonding stake
+func (account *Account) copy() *Account {
+	copied := *account
+	if account.Immature != nil {
+		copied.Immature = append([]ImmatureCredit{}, account.Immature...)
+	}
+
+	if account.Unbonding != nil {
+		copied.Unbonding = append([]UnbondingStake{}, account.Unbonding...)
+	}
+
+	return &copied
+}
+
+// Serialize implements the common.Serializable interface for Account.
+// Converts the Account into a stream of bytes encoded using common.GobEncode.
+func (account *Account) Serialize() ([]byte, error) {
+	return common.GobEncode(account)
+}
+
+// Deserialize implements the common.Serializable interface for Account.
+// Converts the given data into Account and sets it the method's receiver using common.GobDecode.
+func (account *Account) Deserialize(data []byte) error {
+	// Decode the data into a *Account
+	object, err := common.GobDecode(data, new(Account))
+	if err != nil {
+		return err
+	}
+
+	// Cast the object into a *Account and
+	// set it to the method receiver
+	*account = *object.(*Account)
+	return nil
+}
@@ -0,0 +1,130 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// ErrInvalidProof is returned when a Proof does not match the state root it is verified against
+var ErrInvalidProof = errors.New("invalid state proof")
+
+// Proof is a Merkle proof of the Account of an address in the state trie.
+//
+// The proof is the path from the root to the subtree of the address. Siblings contains the hash
+// of the sibling subtree at each depth of the path, starting from the root. The path ends either
+// at an empty subtree, in which case Leaf is nil, or at a leaf. The account exists if the key of
+// the leaf is the key of the address, otherwise the leaf proves that the account does not exist.
+type Proof struct {
+	Siblings []common.Hash
+	Leaf     *ProofLeaf
+}
+
+// ProofLeaf represents the leaf at the end of the path of a Proof
+type ProofLeaf struct {
+	Key     common.Hash
+	Account Account
+}
+
+// Prove returns the Account of the given address and its Proof
+// in the state trie with the given root. An empty Account is
+// returned along with a proof of absence if it does not exist.
+// Returns ErrStatePruned if the trie is not in the DB.
+func Prove(database *db.Database, root common.Hash, address common.Address) (*Account, *Proof, error) {
+	if root != common.NullHash() && !database.Has(nodeKey(root)) {
+		return nil, nil, ErrStatePruned
+	}
+
+	t := newTrie(database, root)
+	key := trieKey(address)
+
+	proof := &Proof{Siblings: make([]common.Hash, 0)}
+	hash := root
+
+	for depth := 0; depth < trieDepth; depth++ {
+		// Empty subtree, the account does not exist
+		if hash == common.NullHash() {
+			return new(Account), proof, nil
+		}
+
+		node, err := t.load(hash)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The path ends at a leaf
+		if node.leaf {
+			proof.Leaf = &ProofLeaf{node.key, node.account}
+			if node.key != key {
+				return new(Account), proof, nil
+			}
+
+			account := node.account
+			return &account, proof, nil
+		}
+
+		// Descend into the subtree of the key and record the sibling
+		if keyBit(key, depth) == 0 {
+			hash = node.left
+			proof.Siblings = append(proof.Siblings, node.right)
+		} else {
+			hash = node.right
+			proof.Siblings = append(proof.Siblings, node.left)
+		}
+	}
+
+	return nil, nil, ErrInvalidNode
+}
+
+// VerifyProof checks the given Proof for the address against the StateRoot of the given header.
+// Returns the Account of the address proven by the Proof, which is empty if the account does not
+// exist. Returns ErrInvalidProof if the proof does not match the state root.
+func VerifyProof(header *core.BlockHeader, address common.Address, proof *Proof) (*Account, error) {
+	key := trieKey(address)
+	depth := len(proof.Siblings)
+
+	if depth >= trieDepth {
+		return nil, fmt.Errorf("%w: path too long", ErrInvalidProof)
+	}
+
+	// Compute the hash of the subtree at the end of the path
+	account := new(Account)
+	hash := common.NullHash()
+
+	if proof.Leaf != nil {
+		// The leaf must be in the subtree of the key
+		for i := 0; i < depth; i++ {
+			if keyBit(proof.Leaf.Key, i) != keyBit(key, i) {
+				return nil, fmt.Errorf("%w: leaf is not on the path of the address", ErrInvalidProof)
+			}
+		}
+
+		// An empty leaf is never stored in the trie
+		if proof.Leaf.Account.Empty() {
+			return nil, fmt.Errorf("%w: empty leaf", ErrInvalidProof)
+		}
+
+		hash = newLeaf(proof.Leaf.Key, proof.Leaf.Account).hash()
+		if proof.Leaf.Key == key {
+			*account = proof.Leaf.Account
+		}
+	}
+
+	// Hash the path from the end to the root
+	for i := depth - 1; i >= 0; i-- {
+		if keyBit(key, i) == 0 {
+			hash = newBranch(hash, proof.Siblings[i]).hash()
+		} else {
+			hash = newBranch(proof.Siblings[i], hash).hash()
+		}
+	}
+
+	if hash != header.StateRoot {
+		return nil, fmt.Errorf("%w: root mismatch", ErrInvalidProof)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+func TestVerifyProof(t *testing.T) {
+	alloc := make(core.GenesisAlloc)
+	for i := 0; i < 16; i++ {
+		alloc[common.Address(fmt.Sprintf("account-%v", i))] = uint64(i + 1)
+	}
+
+	state := newTestState(t, nil, alloc)
+
+	root, err := state.Commit(0)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+
+	header := &core.BlockHeader{StateRoot: root}
+
+	// prove returns the Proof of the given address, modified by the given function
+	prove := func(address common.Address, modify func(proof *Proof)) *Proof {
+		_, proof, err := Prove(state.db, root, address)
+		if err != nil {
+			t.Fatalf("failed to prove account: %v", err)
+		}
+
+		modify(proof)
+		return proof
+	}
+
+	unmodified := func(proof *Proof) {}
+	other := prove("account-1", unmodified)
+
+	tests := []struct {
+		name    string
+		address common.Address
+		proof   *Proof
+		balance uint64
+		want    error
+	}{
+		{"existing account", "account-0", prove("account-0", unmodified), 1, nil},
+		{"other existing account", "account-15", prove("account-15", unmodified), 16, nil},
+		{"absent account", "absent", prove("absent", unmodified), 0, nil},
+		{"tampered balance", "account-0", prove("account-0", func(proof *Proof) { proof.Leaf.Account.Balance++ }), 0, ErrInvalidProof},
+		{"tampered sibling", "account-0", prove("account-0", func(proof *Proof) { proof.Siblings[0][0]++ }), 0, ErrInvalidProof},
+		{"missing sibling", "account-0", prove("account-0", func(proof *Proof) { proof.Siblings = proof.Siblings[1:] }), 0, ErrInvalidProof},
+		{"empty leaf", "account-0", prove("account-0", func(proof *Proof) { proof.Leaf.Account = Account{} }), 0, ErrInvalidProof},
+		{"proof of another account", "account-0", other, 0, ErrInvalidProof},
+		{"absent account without leaf", "absent", prove("absent", func(proof *Proof) { proof.Leaf = nil }), 0, ErrInvalidProof},
+		{"path too long", "account-0", &Proof{Siblings: make([]common.Hash, trieDepth)}, 0, ErrInvalidProof},
+	}
+
+	for _, test := range tests {
+		account, err := VerifyProof(header, test.address, test.proof)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+			continue
+		}
+
+		if test.want == nil && account.Balance != test.balance {
+			t.Errorf("%v: proven balance %v, want %v", test.name, account.Balance, test.balance)
+		}
+	}
+
+	// A valid proof must not verify against another state root
+	if _, err := VerifyProof(&core.BlockHeader{StateRoot: common.Hash256([]byte("root"))}, "account-0", prove("account-0", unmodified)); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("proof against another root: got error %v, want %v", err, ErrInvalidProof)
+	}
+}
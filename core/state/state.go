@@ -0,0 +1,583 @@
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+var (
+	// AccountPrefix is the key prefix for the Account of each address in the DB
+	AccountPrefix = []byte("state-account-")
+	// DiffPrefix is the key prefix for the prior Account values modified by the Block at each height
+	DiffPrefix = []byte("state-diff-")
+	// StateRootKey is the key for the root of the state trie at the chain head in the DB
+	StateRootKey = []byte("state-root")
+)
+
+// ErrStatePruned is returned when the state at some root has been pruned from the DB
+var ErrStatePruned = errors.New("state has been pruned")
+
+// State is a view of the accounts of the chain at its head.
+// Modifications are held in memory until they are written into the DB with Commit.
+type State struct {
+	db *db.Database
+
+	// accounts contains the modified accounts
+	accounts map[common.Address]*Account
+	// originals contains the accounts as they were in
+	// the DB before modification. nil if it did not exist.
+	originals map[common.Address]*Account
+
+	// root is the state root in the DB and trie is the state
+	// trie, opened at the state root when it is first needed
+	root common.Hash
+	trie *trie
+
+	// height is the height of the Block that transactions are applied for
+	height int64
+
+	// staking is the config of the Proof of Stake engine whose staking
+	// rules are applied to transactions with the common.StakingAddress
+	staking *core.PoSConfig
+}
+
+// New returns a new State on the given database
+func New(database *db.Database) *State {
+	return &State{
+		db:        database,
+		accounts:  make(map[common.Address]*Account),
+		originals: make(map[common.Address]*Account),
+	}
+}
+
+// accountKey returns the DB key for the Account of the given address
+func accountKey(address common.Address) []byte {
+	return append(append([]byte{}, AccountPrefix...), address...)
+}
+
+// diffKey returns the DB key for the state diff of the Block at the given height
+func diffKey(height int64) []byte {
+	key := make([]byte, len(DiffPrefix)+8)
+	copy(key, DiffPrefix)
+	binary.BigEndian.PutUint64(key[len(DiffPrefix):], uint64(height))
+
+	return key
+}
+
+// GetAccount returns a copy of the Account for the given address.
+// An empty Account is returned for addresses that do not exist.
+func (state *State) GetAccount(address common.Address) (*Account, error) {
+	account, err := state.account(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return account.copy(), nil
+}
+
+// GetBalance returns the balance of the given address
+func (state *State) GetBalance(address common.Address) (uint64, error) {
+	account, err := state.account(address)
+	if err != nil {
+		return 0, err
+	}
+
+	return account.Balance, nil
+}
+
+// GetNonce returns the nonce expected for the next transaction of the given address
+func (state *State) GetNonce(address common.Address) (uint64, error) {
+	account, err := state.account(address)
+	if err != nil {
+		return 0, err
+	}
+
+	return account.Nonce, nil
+}
+
+// AddBalance credits the given amount to the balance of the address.
+// Returns ErrBalanceOverflow if the balance cannot hold the amount.
+func (state *State) AddBalance(address common.Address, amount uint64) error {
+	account, err := state.account(address)
+	if err != nil {
+		return err
+	}
+
+	if account.Balance > math.MaxUint64-amount {
+		return ErrBalanceOverflow
+	}
+
+	account.Balance += amount
+	return nil
+}
+
+// SubBalance debits the given amount from the balance of the address.
+// Returns ErrInsufficientBalance if the balance spendable at the height of the State is less than the amount.
+func (state *State) SubBalance(address common.Address, amount uint64) error {
+	account, err := state.account(address)
+	if err != nil {
+		return err
+	}
+
+	if account.Spendable(state.height) < amount {
+		return ErrInsufficientBalance
+	}
+
+	account.Balance -= amount
+	return nil
+}
+
+// GetSpendableBalance returns the balance of the given address that can be
+// spent at the height of the State, excluding immature coinbase credits
+func (state *State) GetSpendableBalance(address common.Address) (uint64, error) {
+	account, err := state.account(address)
+	if err != nil {
+		return 0, err
+	}
+
+	return account.Spendable(state.height), nil
+}
+
+// Height returns the height of the Block that transactions are applied for
+func (state *State) Height() int64 {
+	return state.height
+}
+
+// SetHeight sets the height of the Block that transactions are applied for.
+// Coinbase credits are only spendable from their release height onwards.
+func (state *State) SetHeight(height int64) {
+	state.height = height
+}
+
+// SetStaking sets the config of the Proof of Stake engine whose staking rules are applied
+// to transactions with the common.StakingAddress. They are plain transfers if it is not set.
+func (state *State) SetStaking(config *core.PoSConfig) {
+	state.staking = config
+}
+
+// SetNonce sets the nonce expected for the next transaction of the address
+func (state *State) SetNonce(address common.Address, nonce uint64) error {
+	account, err := state.account(address)
+	if err != nil {
+		return err
+	}
+
+	account.Nonce = nonce
+	return nil
+}
+
+// accountDiff represents the value of an Account before it was modified by a Block
+type accountDiff struct {
+	Address common.Address
+	Account Account
+	Exists  bool
+}
+
+// stateDiff represents the state root and the accounts before they were modified by a Block,
+// along with the trie nodes written by the Block and the trie nodes of the prior state root
+// that were replaced by the Block
+type stateDiff struct {
+	Root     common.Hash
+	Accounts []accountDiff
+	Written  []writtenNode
+	Replaced []common.Hash
+}
+
+// Root returns the root of the state trie in the DB, without the modified accounts
+func (state *State) Root() (common.Hash, error) {
+	if _, err := state.openTrie(); err != nil {
+		return common.NullHash(), err
+	}
+
+	return state.root, nil
+}
+
+// IntermediateRoot returns the root of the state trie with the modified accounts.
+// The trie nodes are not written into the DB until the State is committed.
+func (state *State) IntermediateRoot() (common.Hash, error) {
+	t, err := state.openTrie()
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	// Update the modified accounts in a deterministic order
+	root := t.root
+	for _, address := range state.modified() {
+		if root, err = t.update(trieKey(address), *state.accounts[address]); err != nil {
+			return common.NullHash(), fmt.Errorf("state trie update failed: %w", err)
+		}
+	}
+
+	return root, nil
+}
+
+// Commit writes the modified accounts and the state trie into the DB along with a diff of their
+// prior values for the Block at the given height, so that the Block can later be reverted.
+// Returns the new state root. The State is reset and reads from the DB after it is committed.
+func (state *State) Commit(height int64) (common.Hash, error) {
+	// Collect the prior state root
+	prior, err := state.Root()
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	// Update the state trie
+	root, err := state.IntermediateRoot()
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	addresses := state.modified()
+
+	// Record the prior root and value of each modified account
+	diff := stateDiff{Root: prior, Accounts: make([]accountDiff, 0, len(addresses))}
+	for _, address := range addresses {
+		account := accountDiff{Address: address}
+		if original := state.originals[address]; original != nil {
+			account.Account, account.Exists = *original, true
+		}
+
+		diff.Accounts = append(diff.Accounts, account)
+	}
+
+	// Write the state trie nodes
+	if diff.Written, diff.Replaced, err = state.trie.commit(prior, height); err != nil {
+		return common.NullHash(), err
+	}
+
+	// Serialize and store the state diff
+	data, err := common.GobEncode(diff)
+	if err != nil {
+		return common.NullHash(), fmt.Errorf("state diff serialize failed: %w", err)
+	}
+
+	if err := state.db.SetEntry(diffKey(height), data); err != nil {
+		return common.NullHash(), fmt.Errorf("state diff store to db failed: %w", err)
+	}
+
+	// Write the modified accounts
+	for _, address := range addresses {
+		if err := state.writeAccount(address, state.accounts[address]); err != nil {
+			return common.NullHash(), err
+		}
+	}
+
+	// Write the new state root
+	if err := state.db.SetEntry(StateRootKey, root.Bytes()); err != nil {
+		return common.NullHash(), fmt.Errorf("state root store to db failed: %w", err)
+	}
+
+	state.accounts = make(map[common.Address]*Account)
+	state.originals = make(map[common.Address]*Account)
+	state.trie = nil
+
+	return root, nil
+}
+
+// readDiff reads the state diff of the Block at the given height from the DB
+func readDiff(database *db.Database, height int64) (*stateDiff, error) {
+	data, err := database.GetEntry(diffKey(height))
+	if err != nil {
+		return nil, fmt.Errorf("state diff retrieve failed: %w", err)
+	}
+
+	object, err := common.GobDecode(data, new(stateDiff))
+	if err != nil {
+		return nil, fmt.Errorf("state diff deserialize failed: %w", err)
+	}
+
+	return object.(*stateDiff), nil
+}
+
+// Revert restores the accounts, the state root and the trie nodes in the DB to their
+// values before the Block at the given height was committed. The Block cannot be
+// reverted if the state before it has been pruned.
+func Revert(database *db.Database, height int64) error {
+	// Read the state diff of the block
+	diff, err := readDiff(database, height)
+	if err != nil {
+		return err
+	}
+
+	state := New(database)
+
+	// Restore the prior value of each account
+	for _, prior := range diff.Accounts {
+		account := prior.Account
+		if !prior.Exists {
+			account = Account{}
+		}
+
+		if err := state.writeAccount(prior.Address, &account); err != nil {
+			return err
+		}
+	}
+
+	// Remove the trie nodes created by the block and restore the creation height of the nodes that it
+	// created again. Those are also removed if they are no longer part of any state that is not pruned.
+	for _, node := range diff.Written {
+		keep := node.Existed
+		if keep {
+			if keep, err = retained(database, diff, height, node); err != nil {
+				return err
+			}
+		}
+
+		if !keep {
+			if err := database.DeleteEntry(nodeKey(node.Hash)); err != nil {
+				return fmt.Errorf("trie node removal failed: %w", err)
+			}
+
+			continue
+		}
+
+		data, _, err := readNode(database, node.Hash)
+		if err != nil {
+			return err
+		}
+
+		var suffix [8]byte
+		binary.BigEndian.PutUint64(suffix[:], uint64(node.Created))
+
+		if err := database.SetEntry(nodeKey(node.Hash), append(data, suffix[:]...)); err != nil {
+			return fmt.Errorf("trie node store to db failed: %w", err)
+		}
+	}
+
+	// Restore the prior state root, its trie nodes are still in the DB
+	if err := database.SetEntry(StateRootKey, diff.Root.Bytes()); err != nil {
+		return fmt.Errorf("state root store to db failed: %w", err)
+	}
+
+	// Remove the state diff of the block
+	if err := database.DeleteEntry(diffKey(height)); err != nil {
+		return fmt.Errorf("state diff removal failed: %w", err)
+	}
+
+	return nil
+}
+
+// retained returns whether the given trie node, which existed before it was created again by the Block
+// at the given height with the given diff, is part of a state that is not pruned once the Block is reverted.
+// Prune keeps nodes that are created again by a later Block, so a node that was replaced by a pruned Block
+// is only in the DB for the reverted Block.
+func retained(database *db.Database, diff *stateDiff, height int64, node writtenNode) (bool, error) {
+	// The states since the node was created are not pruned, so it is removed by Prune once it is replaced
+	if HasDiff(database, node.Created+1) {
+		return true, nil
+	}
+
+	// The node is part of the state before the Block
+	if contains, err := containsNode(database, diff.Root, node.Hash); err != nil || contains {
+		return contains, err
+	}
+
+	// The node was replaced by a Block that is not pruned, so it is removed when that Block is pruned
+	for prior := height - 1; prior > node.Created && HasDiff(database, prior); prior-- {
+		priorDiff, err := readDiff(database, prior)
+		if err != nil {
+			return false, err
+		}
+
+		for _, hash := range priorDiff.Replaced {
+			if hash == node.Hash {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// containsNode returns whether the trie with the given root contains the node with the given hash.
+// The node is looked up on the path of a key in its subtree, which is complete if it is part of the trie.
+func containsNode(database *db.Database, root, hash common.Hash) (bool, error) {
+	t := newTrie(database, root)
+
+	// Find a leaf in the subtree of the node
+	leaf, err := t.load(hash)
+	for err == nil && !leaf.leaf {
+		child := leaf.left
+		if child == common.NullHash() {
+			child = leaf.right
+		}
+
+		leaf, err = t.load(child)
+	}
+
+	switch {
+	case errors.Is(err, db.ErrKeyNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	// Walk the path of the key of the leaf from the root
+	current := root
+	for depth := 0; depth < trieDepth && current != common.NullHash(); depth++ {
+		if current == hash {
+			return true, nil
+		}
+
+		node, err := t.load(current)
+		if err != nil {
+			return false, err
+		}
+
+		if node.leaf {
+			return false, nil
+		}
+
+		if keyBit(leaf.key, depth) == 0 {
+			current = node.left
+		} else {
+			current = node.right
+		}
+	}
+
+	return false, nil
+}
+
+// Prune removes the state before the Block at the given height from the DB.
+// The trie nodes replaced by the Block are removed unless they were created again by a later
+// Block, along with the state diff of the Block, after which the Block can no longer be reverted.
+func Prune(database *db.Database, height int64) error {
+	// Read the state diff of the block
+	diff, err := readDiff(database, height)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range diff.Replaced {
+		_, created, err := readNode(database, hash)
+		if err != nil {
+			// The node has already been removed
+			if errors.Is(err, db.ErrKeyNotFound) {
+				continue
+			}
+
+			return err
+		}
+
+		// The node is part of a later state if it was created again after it was replaced
+		if created >= height {
+			continue
+		}
+
+		if err := database.DeleteEntry(nodeKey(hash)); err != nil {
+			return fmt.Errorf("trie node removal failed: %w", err)
+		}
+	}
+
+	// Remove the state diff of the block
+	if err := database.DeleteEntry(diffKey(height)); err != nil {
+		return fmt.Errorf("state diff removal failed: %w", err)
+	}
+
+	return nil
+}
+
+// HasDiff returns whether the DB contains the state diff for the Block at the given height
+func HasDiff(database *db.Database, height int64) bool {
+	return database.Has(diffKey(height))
+}
+
+// GetAccountAt returns the Account of the given address in the state trie with the given root.
+// An empty Account is returned if it does not exist. Returns ErrStatePruned if the trie is not in the DB.
+func GetAccountAt(database *db.Database, root common.Hash, address common.Address) (*Account, error) {
+	if root != common.NullHash() && !database.Has(nodeKey(root)) {
+		return nil, ErrStatePruned
+	}
+
+	return newTrie(database, root).get(trieKey(address))
+}
+
+// modified returns the addresses of the modified accounts in ascending order
+func (state *State) modified() []common.Address {
+	addresses := make([]common.Address, 0, len(state.accounts))
+	for address := range state.accounts {
+		addresses = append(addresses, address)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i] < addresses[j] })
+	return addresses
+}
+
+// openTrie returns the state trie, opening it at the state root in the DB if it has not been opened yet
+func (state *State) openTrie() (*trie, error) {
+	if state.trie != nil {
+		return state.trie, nil
+	}
+
+	// Read the state root, a missing root is an empty trie
+	root := common.NullHash()
+	data, err := state.db.GetEntry(StateRootKey)
+	switch {
+	case err == nil:
+		root = common.BytesToHash(data)
+	case !errors.Is(err, db.ErrKeyNotFound):
+		return nil, fmt.Errorf("state root retrieve failed: %w", err)
+	}
+
+	state.root, state.trie = root, newTrie(state.db, root)
+	return state.trie, nil
+}
+
+// account returns the modifiable Account for the given address,
+// reading it from the DB if it has not been accessed yet.
+func (state *State) account(address common.Address) (*Account, error) {
+	if account, ok := state.accounts[address]; ok {
+		return account, nil
+	}
+
+	account := new(Account)
+
+	// Read the account from the DB, a missing account is empty
+	data, err := state.db.GetEntry(accountKey(address))
+	switch {
+	case err == nil:
+		if err := account.Deserialize(data); err != nil {
+			return nil, fmt.Errorf("account deserialize failed: %w", err)
+		}
+
+		state.originals[address] = account.copy()
+
+	case errors.Is(err, db.ErrKeyNotFound):
+		state.originals[address] = nil
+
+	default:
+		return nil, fmt.Errorf("account retrieve failed: %w", err)
+	}
+
+	state.accounts[address] = account
+	return account, nil
+}
+
+// writeAccount stores the given Account into the DB. Empty accounts are removed from the DB.
+func (state *State) writeAccount(address common.Address, account *Account) error {
+	if account.Empty() {
+		if err := state.db.DeleteEntry(accountKey(address)); err != nil && !errors.Is(err, db.ErrKeyNotFound) {
+			return fmt.Errorf("account removal failed: %w", err)
+		}
+
+		return nil
+	}
+
+	data, err := account.Serialize()
+	if err != nil {
+		return fmt.Errorf("account serialize failed: %w", err)
+	}
+
+	if err := state.db.SetEntry(accountKey(address), data); err != nil {
+		return fmt.Errorf("account store to db failed: %w", err)
+	}
+
+	return nil
+}
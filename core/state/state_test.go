@@ -0,0 +1,189 @@
+package state
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// testHead is the height of the last block committed by newTestHistory
+const testHead = 8
+
+// testBalances returns the balances of the accounts after the block at the given height in
+// newTestHistory. The balance of "a" alternates, so that its trie nodes are created again,
+// and "c" only exists after the blocks at even heights, so that its leaf is removed.
+func testBalances(height int64) map[common.Address]uint64 {
+	balances := map[common.Address]uint64{"a": 1 + uint64(height%2), "b": uint64(height + 1)}
+	if height%2 == 0 {
+		balances["c"] = 5
+	}
+
+	return balances
+}
+
+// newTestHistory returns a database with the state of the blocks up to testHead
+// committed, along with the state roots of the blocks and the hashes of all the trie
+// nodes written into the database
+func newTestHistory(t *testing.T) (*db.Database, []common.Hash, map[common.Hash]struct{}) {
+	t.Helper()
+
+	database := db.OpenMemory()
+	t.Cleanup(func() { database.Close() })
+
+	roots := make([]common.Hash, testHead+1)
+	nodes := make(map[common.Hash]struct{})
+
+	for height := int64(0); height <= testHead; height++ {
+		state := New(database)
+		state.SetHeight(height)
+
+		for _, address := range []common.Address{"a", "b", "c"} {
+			account, err := state.account(address)
+			if err != nil {
+				t.Fatalf("failed to get account: %v", err)
+			}
+
+			account.Balance = testBalances(height)[address]
+		}
+
+		root, err := state.Commit(height)
+		if err != nil {
+			t.Fatalf("failed to commit state %v: %v", height, err)
+		}
+
+		diff, err := readDiff(database, height)
+		if err != nil {
+			t.Fatalf("failed to read diff %v: %v", height, err)
+		}
+
+		for _, node := range diff.Written {
+			nodes[node.Hash] = struct{}{}
+		}
+
+		roots[height] = root
+	}
+
+	return database, roots, nodes
+}
+
+// reachable returns the hashes of the trie nodes of the tries with the given roots
+func reachable(t *testing.T, database *db.Database, roots []common.Hash) map[common.Hash]struct{} {
+	t.Helper()
+
+	nodes := make(map[common.Hash]struct{})
+	pending := append([]common.Hash{}, roots...)
+
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if _, ok := nodes[hash]; ok || hash == common.NullHash() {
+			continue
+		}
+
+		nodes[hash] = struct{}{}
+
+		node, err := newTrie(database, common.NullHash()).load(hash)
+		if err != nil {
+			t.Fatalf("failed to load reachable node %v: %v", hash.Hex(), err)
+		}
+
+		if !node.leaf {
+			pending = append(pending, node.left, node.right)
+		}
+	}
+
+	return nodes
+}
+
+func TestPruneRevert(t *testing.T) {
+	tests := []struct {
+		name   string
+		prune  int64
+		revert int64
+		want   error
+	}{
+		{"no changes", 0, testHead, nil},
+		{"revert one block", 0, testHead - 1, nil},
+		{"revert to a repeated balance", 0, 3, nil},
+		{"revert to genesis", 0, 0, nil},
+		{"prune", 4, testHead, nil},
+		{"prune all but the head", testHead, testHead, nil},
+		{"prune and revert", 4, 4, nil},
+		{"revert a pruned block", 4, 3, db.ErrKeyNotFound},
+		{"revert all pruned blocks", testHead, 0, db.ErrKeyNotFound},
+	}
+
+	for _, test := range tests {
+		database, roots, written := newTestHistory(t)
+
+		// Prune the state before the blocks up to the given height
+		for height := int64(1); height <= test.prune; height++ {
+			if err := Prune(database, height); err != nil {
+				t.Fatalf("%v: failed to prune %v: %v", test.name, height, err)
+			}
+		}
+
+		// Revert the blocks above the given height from the latest
+		var err error
+		for height := int64(testHead); height > test.revert && err == nil; height-- {
+			err = Revert(database, height)
+		}
+
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+			continue
+		}
+
+		if test.want != nil {
+			continue
+		}
+
+		// The state root and accounts must be restored to their values at the new head
+		if data, err := database.GetEntry(StateRootKey); err != nil || common.BytesToHash(data) != roots[test.revert] {
+			t.Errorf("%v: state root is not the root of block %v", test.name, test.revert)
+		}
+
+		state := New(database)
+		for _, address := range []common.Address{"a", "b", "c"} {
+			if got, _ := state.GetBalance(address); got != testBalances(test.revert)[address] {
+				t.Errorf("%v: balance of %v is %v, want %v", test.name, address, got, testBalances(test.revert)[address])
+			}
+		}
+
+		// The states that were neither pruned nor reverted must remain readable
+		live := roots[test.prune : test.revert+1]
+		for offset, root := range live {
+			height := test.prune + int64(offset)
+
+			for address, balance := range testBalances(height) {
+				account, err := GetAccountAt(database, root, address)
+				if err != nil || account.Balance != balance {
+					t.Errorf("%v: account %v at %v is %+v (error %v), want balance %v", test.name, address, height, account, err, balance)
+				}
+			}
+		}
+
+		// The DB must contain exactly the trie nodes of those states
+		present := make(map[common.Hash]struct{})
+		for hash := range written {
+			if database.Has(nodeKey(hash)) {
+				present[hash] = struct{}{}
+			}
+		}
+
+		if want := reachable(t, database, live); !reflect.DeepEqual(present, want) {
+			t.Errorf("%v: DB has %v trie nodes, want the %v nodes of the remaining states", test.name, len(present), len(want))
+		}
+
+		// The diffs of the reverted and pruned blocks must be removed
+		for height := int64(1); height <= testHead; height++ {
+			if want := height > test.prune && height <= test.revert; HasDiff(database, height) != want {
+				t.Errorf("%v: diff of block %v exists %v, want %v", test.name, height, !want, want)
+			}
+		}
+	}
+}
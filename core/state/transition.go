@@ -0,0 +1,294 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+)
+
+var (
+	// ErrInsufficientBalance is returned when an account does not have the balance for a debit
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrImmatureBalance is returned when a debit can only be paid with coinbase credits that are not spendable yet
+	ErrImmatureBalance = errors.New("balance includes immature coinbase credits")
+	// ErrBalanceOverflow is returned when a credit overflows the balance of an account
+	ErrBalanceOverflow = errors.New("balance overflow")
+	// ErrNonceTooLow is returned when a transaction nonce has already been used by the sender
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrNonceTooHigh is returned when a transaction nonce skips the next nonce of the sender
+	ErrNonceTooHigh = errors.New("nonce too high")
+	// ErrUnauthorizedMint is returned for a mint transaction that is not the coinbase of a block
+	ErrUnauthorizedMint = errors.New("mint transaction is not the coinbase")
+	// ErrNoStake is returned when an account without any bonded stake unbonds
+	ErrNoStake = errors.New("no bonded stake")
+	// ErrLastValidator is returned when an unbond would leave no account with the minimum stake to propose blocks
+	ErrLastValidator = errors.New("unbond would leave no validator with the minimum stake")
+	// ErrInvalidRelease is returned for a transaction from the staking address that does not release matured stake
+	ErrInvalidRelease = errors.New("transaction does not release matured stake")
+)
+
+// TxnError is the error returned when a Transaction of a Block fails the state transition
+type TxnError struct {
+	// Represents the index of the transaction in the block
+	Index int
+	// Represents the hash of the transaction
+	Hash common.Hash
+	// Represents the state transition error
+	Err error
+}
+
+// Error implements the error interface for TxnError
+func (err *TxnError) Error() string {
+	return fmt.Sprintf("transaction %v [%v] failed: %v", err.Index, err.Hash.Hex(), err.Err)
+}
+
+// Unwrap returns the state transition error of the TxnError
+func (err *TxnError) Unwrap() error {
+	return err.Err
+}
+
+// IsMint returns whether the given Transaction mints its value for the sender.
+// Mint transactions are sent to the NullAddress, as done by coinbase and genesis allocations.
+func IsMint(txn *core.Transaction) bool {
+	return txn.To == common.NullAddress()
+}
+
+// ApplyTransaction applies the given transfer Transaction to the State.
+// The value is moved from the sender to the receiver, the fee is deducted from the sender
+// and the sender nonce is incremented. The fee is credited to the miner by the coinbase.
+// The State is not modified if the transaction is invalid, which is the case if:
+//   - it is a mint transaction (ErrUnauthorizedMint)
+//   - its nonce is not the next nonce of the sender (ErrNonceTooLow or ErrNonceTooHigh)
+//   - the sender balance is less than the value plus the fee (ErrInsufficientBalance)
+//   - the sender balance is only enough with its immature coinbase credits (ErrImmatureBalance)
+//   - the receiver balance cannot hold the value (ErrBalanceOverflow)
+//   - it breaks the staking rules of the State, as described by applyStaking
+func (state *State) ApplyTransaction(txn *core.Transaction) error {
+	// Only the coinbase of a block can mint tokens
+	if IsMint(txn) {
+		return ErrUnauthorizedMint
+	}
+
+	sender, err := state.account(txn.From)
+	if err != nil {
+		return err
+	}
+
+	// Check that the nonce is the next nonce of the sender
+	switch {
+	case txn.Nonce < sender.Nonce:
+		return ErrNonceTooLow
+	case txn.Nonce > sender.Nonce:
+		return ErrNonceTooHigh
+	}
+
+	// Check that the sender can pay the value and the fee
+	if err := state.CheckBalance(txn); err != nil {
+		return err
+	}
+
+	receiver, err := state.account(txn.To)
+	if err != nil {
+		return err
+	}
+
+	// Check that the receiver can hold the value, a transfer to self leaves the balance unchanged
+	if txn.From != txn.To && receiver.Balance > math.MaxUint64-txn.Value {
+		return ErrBalanceOverflow
+	}
+
+	// Check the staking rules before any account is modified
+	if err := state.checkStaking(txn, sender, receiver); err != nil {
+		return err
+	}
+
+	// Move the value, deduct the fee and increment the sender nonce
+	sender.Balance -= txn.Value + txn.Fee
+	receiver.Balance += txn.Value
+	sender.Nonce++
+	sender.mature(state.height)
+
+	state.applyStaking(txn, sender, receiver)
+	return nil
+}
+
+// checkStaking checks the given Transaction against the staking rules of the State.
+// A transaction from the common.StakingAddress must release matured unbonding stake of the
+// receiver without a fee (ErrInvalidRelease). An unbond must be sent by an account with bonded
+// stake (ErrNoStake) and must leave some account with the minimum stake (ErrLastValidator).
+func (state *State) checkStaking(txn *core.Transaction, sender, receiver *Account) error {
+	if state.staking == nil {
+		return nil
+	}
+
+	switch {
+	case txn.From == common.StakingAddress():
+		if txn.Fee != 0 || receiver.released(txn.Value, state.height) < 0 {
+			return ErrInvalidRelease
+		}
+
+	case txn.To == common.StakingAddress() && txn.Value == 0:
+		if sender.Stake == 0 {
+			return ErrNoStake
+		}
+
+		// The receiver is the staking address, which holds the total stake of the validators
+		if sender.Stake >= state.staking.MinimumStake && receiver.Stake == sender.Stake {
+			return ErrLastValidator
+		}
+	}
+
+	return nil
+}
+
+// applyStaking applies the staking rules of the State for the given Transaction, which has passed checkStaking.
+// A transaction to the common.StakingAddress with a value bonds the value as stake of the sender, while one
+// without a value unbonds all the stake of the sender until the unbonding period has passed. A transaction
+// from the common.StakingAddress releases the unbonding stake back to the receiver.
+func (state *State) applyStaking(txn *core.Transaction, sender, receiver *Account) {
+	if state.staking == nil {
+		return
+	}
+
+	switch {
+	case txn.From == common.StakingAddress():
+		index := receiver.released(txn.Value, state.height)
+		receiver.Unbonding = append(receiver.Unbonding[:index:index], receiver.Unbonding[index+1:]...)
+
+	case txn.To == common.StakingAddress() && txn.Value > 0:
+		state.bond(sender, receiver, txn.Value)
+
+	case txn.To == common.StakingAddress():
+		if sender.Stake >= state.staking.MinimumStake {
+			receiver.Stake -= sender.Stake
+		}
+
+		sender.Unbonding = append(sender.Unbonding, UnbondingStake{sender.Stake, state.height + state.staking.UnbondingPeriod})
+		sender.Stake = 0
+	}
+}
+
+// bond adds the given amount to the stake of the given account and updates the total stake
+// of the validators, which is held by the given Account of the common.StakingAddress
+func (state *State) bond(account, staking *Account, amount uint64) {
+	if account.Stake >= state.staking.MinimumStake {
+		staking.Stake -= account.Stake
+	}
+
+	account.Stake += amount
+	if account.Stake >= state.staking.MinimumStake {
+		staking.Stake += account.Stake
+	}
+}
+
+// bondValidators bonds the initial stake of the validators of the
+// staking config, which the genesis block mints to the common.StakingAddress
+func (state *State) bondValidators() error {
+	staking, err := state.account(common.StakingAddress())
+	if err != nil {
+		return err
+	}
+
+	// Bond the stakes in a deterministic order
+	validators := make([]common.Address, 0, len(state.staking.Validators))
+	for validator := range state.staking.Validators {
+		validators = append(validators, validator)
+	}
+
+	sort.Slice(validators, func(i, j int) bool { return validators[i] < validators[j] })
+
+	for _, validator := range validators {
+		account, err := state.account(validator)
+		if err != nil {
+			return err
+		}
+
+		state.bond(account, staking, state.staking.Validators[validator])
+	}
+
+	return nil
+}
+
+// CheckBalance checks that the sender of the given Transaction can pay its value and fee with the
+// balance that is spendable at the height of the State. Returns ErrInsufficientBalance if the balance
+// is not enough or ErrImmatureBalance if it is only enough with immature coinbase credits.
+func (state *State) CheckBalance(txn *core.Transaction) error {
+	sender, err := state.account(txn.From)
+	if err != nil {
+		return err
+	}
+
+	if txn.Value > math.MaxUint64-txn.Fee || sender.Balance < txn.Value+txn.Fee {
+		return ErrInsufficientBalance
+	}
+
+	if sender.Spendable(state.height) < txn.Value+txn.Fee {
+		return ErrImmatureBalance
+	}
+
+	return nil
+}
+
+// ApplyMint applies the given mint Transaction to the State, crediting its value to the sender.
+// The State is not modified if the balance cannot hold the value (ErrBalanceOverflow).
+func (state *State) ApplyMint(txn *core.Transaction) error {
+	return state.AddBalance(txn.From, txn.Value)
+}
+
+// ApplyCoinbase applies the given coinbase Transaction to the State, crediting its value to the sender.
+// The credit is not spendable until the given maturity number of blocks after the height of the State.
+// The State is not modified if the balance cannot hold the value (ErrBalanceOverflow).
+func (state *State) ApplyCoinbase(txn *core.Transaction, maturity int64) error {
+	if err := state.AddBalance(txn.From, txn.Value); err != nil {
+		return err
+	}
+
+	account, err := state.account(txn.From)
+	if err != nil {
+		return err
+	}
+
+	// Drop the released credits and lock the new credit until it matures
+	account.mature(state.height)
+	if maturity > 0 && txn.Value > 0 {
+		account.Immature = append(account.Immature, ImmatureCredit{txn.Value, state.height + maturity})
+	}
+
+	return nil
+}
+
+// ApplyBlock applies all the Transactions of the given Block to the State in order.
+// Mint transactions are only accepted as the first transaction (the coinbase) of a block,
+// whose value matures after the given number of blocks, or anywhere in the genesis block
+// for its allocations. The genesis block also bonds the initial stake of the validators if the
+// State has staking rules. A *TxnError is returned for the first transaction that fails,
+// the State must be discarded in that case.
+func (state *State) ApplyBlock(block *core.Block, maturity int64) error {
+	state.height = block.BlockHeight
+
+	for index, txn := range block.BlockTxns {
+		var err error
+		switch {
+		case IsMint(txn) && index == 0:
+			err = state.ApplyCoinbase(txn, maturity)
+		case IsMint(txn) && block.BlockHeight == 0:
+			err = state.ApplyMint(txn)
+		default:
+			err = state.ApplyTransaction(txn)
+		}
+
+		if err != nil {
+			return &TxnError{index, txn.Hash(), err}
+		}
+	}
+
+	if block.BlockHeight == 0 && state.staking != nil {
+		return state.bondValidators()
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// testChainID is the chain ID of the transactions in the tests
+const testChainID = 1
+
+// newTestState returns a State on an in-memory database with the given staking rules,
+// after applying a genesis block that mints the given allocations
+func newTestState(t *testing.T, staking *core.PoSConfig, alloc core.GenesisAlloc) *State {
+	t.Helper()
+
+	addresses := make([]common.Address, 0, len(alloc))
+	for address := range alloc {
+		addresses = append(addresses, address)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i] < addresses[j] })
+
+	txns := make(core.Transactions, 0, len(addresses))
+	for _, address := range addresses {
+		txns = append(txns, core.NewMintTransaction(testChainID, address, alloc[address]))
+	}
+
+	genesis, err := core.NewBlock(txns, common.NullHash(), 0)
+	if err != nil {
+		t.Fatalf("failed to create genesis block: %v", err)
+	}
+
+	database := db.OpenMemory()
+	t.Cleanup(func() { database.Close() })
+
+	state := New(database)
+	state.SetStaking(staking)
+
+	if err := state.ApplyBlock(genesis, 0); err != nil {
+		t.Fatalf("failed to apply genesis block: %v", err)
+	}
+
+	return state
+}
+
+// testStep is a transaction applied on a State at some height by the tests
+type testStep struct {
+	height int64
+	txn    *core.Transaction
+}
+
+// applySteps applies the given steps on the State and returns the error of the first step that fails
+func applySteps(state *State, steps []testStep) error {
+	for _, step := range steps {
+		state.SetHeight(step.height)
+
+		if err := state.ApplyTransaction(step.txn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestStakingRules(t *testing.T) {
+	staking := &core.PoSConfig{UnbondingPeriod: 2, MinimumStake: 100, Validators: map[common.Address]uint64{"a": 100}}
+	alloc := core.GenesisAlloc{"a": 1000, "b": 1000, common.StakingAddress(): 100}
+
+	var (
+		bondB    = core.NewTransaction(testChainID, "b", common.StakingAddress(), 0, 100, 1)
+		unbondB  = core.NewTransaction(testChainID, "b", common.StakingAddress(), 1, 0, 1)
+		releaseB = core.NewTransaction(testChainID, common.StakingAddress(), "b", 0, 100, 0)
+	)
+
+	tests := []struct {
+		name      string
+		steps     []testStep
+		want      error
+		stakes    map[common.Address]uint64
+		unbonding []UnbondingStake
+	}{
+		{
+			"bond", []testStep{{1, bondB}}, nil,
+			map[common.Address]uint64{"a": 100, "b": 100, common.StakingAddress(): 200}, nil,
+		},
+		{
+			"bond below minimum", []testStep{{1, core.NewTransaction(testChainID, "b", common.StakingAddress(), 0, 50, 1)}}, nil,
+			map[common.Address]uint64{"a": 100, "b": 50, common.StakingAddress(): 100}, nil,
+		},
+		{
+			"unbond", []testStep{{1, bondB}, {2, unbondB}}, nil,
+			map[common.Address]uint64{"a": 100, "b": 0, common.StakingAddress(): 100}, []UnbondingStake{{100, 4}},
+		},
+		{
+			"release", []testStep{{1, bondB}, {2, unbondB}, {4, releaseB}}, nil,
+			map[common.Address]uint64{"a": 100, "b": 0, common.StakingAddress(): 100}, nil,
+		},
+		{
+			"unbond without stake", []testStep{{1, core.NewTransaction(testChainID, "b", common.StakingAddress(), 0, 0, 1)}},
+			ErrNoStake, nil, nil,
+		},
+		{
+			"unbond last validator", []testStep{{1, core.NewTransaction(testChainID, "a", common.StakingAddress(), 0, 0, 1)}},
+			ErrLastValidator, nil, nil,
+		},
+		{
+			"release before the unbonding period", []testStep{{1, bondB}, {2, unbondB}, {3, releaseB}},
+			ErrInvalidRelease, nil, nil,
+		},
+		{
+			"release with fee", []testStep{{1, bondB}, {2, unbondB}, {4, core.NewTransaction(testChainID, common.StakingAddress(), "b", 0, 100, 1)}},
+			ErrInvalidRelease, nil, nil,
+		},
+		{
+			"release of another amount", []testStep{{1, bondB}, {2, unbondB}, {4, core.NewTransaction(testChainID, common.StakingAddress(), "b", 0, 50, 0)}},
+			ErrInvalidRelease, nil, nil,
+		},
+		{
+			"release without unbond", []testStep{{1, releaseB}},
+			ErrInvalidRelease, nil, nil,
+		},
+	}
+
+	for _, test := range tests {
+		state := newTestState(t, staking, alloc)
+
+		if err := applySteps(state, test.steps); !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+			continue
+		}
+
+		for address, stake := range test.stakes {
+			account, err := state.GetAccount(address)
+			if err != nil {
+				t.Fatalf("%v: failed to get account: %v", test.name, err)
+			}
+
+			if account.Stake != stake {
+				t.Errorf("%v: stake of %v is %v, want %v", test.name, address, account.Stake, stake)
+			}
+		}
+
+		if test.want != nil {
+			continue
+		}
+
+		account, err := state.GetAccount("b")
+		if err != nil {
+			t.Fatalf("%v: failed to get account: %v", test.name, err)
+		}
+
+		if (len(account.Unbonding) != 0 || len(test.unbonding) != 0) && !reflect.DeepEqual(account.Unbonding, test.unbonding) {
+			t.Errorf("%v: unbonding stake %v, want %v", test.name, account.Unbonding, test.unbonding)
+		}
+	}
+}
+
+func TestApplyTransaction(t *testing.T) {
+	alloc := core.GenesisAlloc{"a": 1000, "b": math.MaxUint64 - 100}
+
+	tests := []struct {
+		name     string
+		txn      *core.Transaction
+		want     error
+		balances map[common.Address]uint64
+	}{
+		{"transfer", core.NewTransaction(testChainID, "a", "c", 0, 100, 10), nil, map[common.Address]uint64{"a": 890, "c": 100}},
+		{"transfer of the balance", core.NewTransaction(testChainID, "a", "c", 0, 990, 10), nil, map[common.Address]uint64{"a": 0, "c": 990}},
+		{"transfer to self", core.NewTransaction(testChainID, "a", "a", 0, 100, 10), nil, map[common.Address]uint64{"a": 990}},
+		{"mint", core.NewMintTransaction(testChainID, "a", 100), ErrUnauthorizedMint, nil},
+		{"nonce too high", core.NewTransaction(testChainID, "a", "c", 1, 100, 10), ErrNonceTooHigh, nil},
+		{"insufficient balance", core.NewTransaction(testChainID, "a", "c", 0, 1000, 1), ErrInsufficientBalance, nil},
+		{"empty sender", core.NewTransaction(testChainID, "c", "a", 0, 1, 0), ErrInsufficientBalance, nil},
+		{"value and fee overflow", core.NewTransaction(testChainID, "a", "c", 0, math.MaxUint64, 1), ErrInsufficientBalance, nil},
+		{"receiver overflow", core.NewTransaction(testChainID, "a", "b", 0, 101, 0), ErrBalanceOverflow, nil},
+	}
+
+	for _, test := range tests {
+		state := newTestState(t, nil, alloc)
+		state.SetHeight(1)
+
+		before := accounts(t, state, "a", "b", "c")
+
+		err := state.ApplyTransaction(test.txn)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+			continue
+		}
+
+		// A rejected transaction must not modify the State
+		if test.want != nil {
+			if after := accounts(t, state, "a", "b", "c"); !reflect.DeepEqual(after, before) {
+				t.Errorf("%v: state modified by a rejected transaction", test.name)
+			}
+
+			continue
+		}
+
+		for address, balance := range test.balances {
+			if got, _ := state.GetBalance(address); got != balance {
+				t.Errorf("%v: balance of %v is %v, want %v", test.name, address, got, balance)
+			}
+		}
+
+		if nonce, _ := state.GetNonce(test.txn.From); nonce != 1 {
+			t.Errorf("%v: sender nonce is %v, want 1", test.name, nonce)
+		}
+
+		// The nonce of an applied transaction cannot be used again
+		if err := state.ApplyTransaction(test.txn); !errors.Is(err, ErrNonceTooLow) {
+			t.Errorf("%v: replay got error %v, want %v", test.name, err, ErrNonceTooLow)
+		}
+	}
+}
+
+func TestApplyBlock(t *testing.T) {
+	coinbase := core.NewMintTransaction(testChainID, "m", 50)
+	transfer := core.NewTransaction(testChainID, "a", "c", 0, 100, 0)
+
+	tests := []struct {
+		name  string
+		txns  core.Transactions
+		index int
+		want  error
+	}{
+		{"coinbase and transfers", core.Transactions{coinbase, transfer, core.NewTransaction(testChainID, "a", "c", 1, 100, 0)}, 0, nil},
+		{"transfers", core.Transactions{transfer}, 0, nil},
+		{"mint after the coinbase", core.Transactions{coinbase, core.NewMintTransaction(testChainID, "a", 50)}, 1, ErrUnauthorizedMint},
+		{"duplicate nonce", core.Transactions{coinbase, transfer, transfer}, 2, ErrNonceTooLow},
+		{"insufficient balance", core.Transactions{coinbase, core.NewTransaction(testChainID, "c", "a", 0, 1, 0)}, 1, ErrInsufficientBalance},
+	}
+
+	for _, test := range tests {
+		state := newTestState(t, nil, core.GenesisAlloc{"a": 1000})
+
+		block, err := core.NewBlock(test.txns, common.NullHash(), 1)
+		if err != nil {
+			t.Fatalf("%v: failed to create block: %v", test.name, err)
+		}
+
+		err = state.ApplyBlock(block, 0)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+			continue
+		}
+
+		// A rejected block reports the index and hash of the failed transaction
+		if test.want != nil {
+			var txnErr *TxnError
+			if !errors.As(err, &txnErr) || txnErr.Index != test.index || txnErr.Hash != test.txns[test.index].Hash() {
+				t.Errorf("%v: got error %v, want a TxnError for transaction %v", test.name, err, test.index)
+			}
+		}
+	}
+}
+
+// accounts returns copies of the Accounts of the given addresses in the State
+func accounts(t *testing.T, state *State, addresses ...common.Address) []*Account {
+	t.Helper()
+
+	copies := make([]*Account, 0, len(addresses))
+	for _, address := range addresses {
+		account, err := state.GetAccount(address)
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+
+		copies = append(copies, account)
+	}
+
+	return copies
+}
+
+func TestCoinbaseMaturity(t *testing.T) {
+	tests := []struct {
+		name      string
+		maturity  int64
+		height    int64
+		spend     uint64
+		spendable uint64
+		want      error
+	}{
+		{"allocation", 3, 2, 50, 50, nil},
+		{"immature credits", 3, 2, 51, 50, ErrImmatureBalance},
+		{"immature credits before the release", 3, 3, 51, 50, ErrImmatureBalance},
+		{"first credit matured", 3, 4, 150, 150, nil},
+		{"second credit immature", 3, 4, 151, 150, ErrImmatureBalance},
+		{"all credits matured", 3, 5, 250, 250, nil},
+		{"insufficient balance", 3, 5, 251, 250, ErrInsufficientBalance},
+		{"no maturity", 0, 2, 250, 250, nil},
+	}
+
+	for _, test := range tests {
+		state := newTestState(t, nil, core.GenesisAlloc{"m": 50})
+
+		// Credit the block reward of the blocks at height 1 and 2 to the miner
+		for height := int64(1); height <= 2; height++ {
+			block, err := core.NewBlock(core.Transactions{core.NewMintTransaction(testChainID, "m", 100)}, common.NullHash(), height)
+			if err != nil {
+				t.Fatalf("%v: failed to create block: %v", test.name, err)
+			}
+
+			if err := state.ApplyBlock(block, test.maturity); err != nil {
+				t.Fatalf("%v: failed to apply block: %v", test.name, err)
+			}
+		}
+
+		state.SetHeight(test.height)
+
+		if spendable, _ := state.GetSpendableBalance("m"); spendable != test.spendable {
+			t.Errorf("%v: spendable balance %v, want %v", test.name, spendable, test.spendable)
+		}
+
+		// The admission check and the state transition must agree
+		txn := core.NewTransaction(testChainID, "m", "r", 0, test.spend-1, 1)
+		if err := state.CheckBalance(txn); !errors.Is(err, test.want) {
+			t.Errorf("%v: balance check got error %v, want %v", test.name, err, test.want)
+		}
+
+		if err := state.ApplyTransaction(txn); !errors.Is(err, test.want) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.want)
+		}
+	}
+}
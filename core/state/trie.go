@@ -0,0 +1,487 @@
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// NodePrefix is the key prefix for the nodes of the state trie in the DB, indexed by their hash
+var NodePrefix = []byte("state-node-")
+
+const (
+	// trieDepth is the number of bits in a trie key
+	trieDepth = 8 * common.HashLength
+
+	// leafNodeLength is the length of an encoded leaf node without immature credits or unbonding stake
+	leafNodeLength = 1 + common.HashLength + 28
+	// creditLength is the length of an encoded immature credit or unbonding stake of a leaf node
+	creditLength = 16
+	// branchNodeLength is the length of an encoded branch node
+	branchNodeLength = 1 + 2*common.HashLength
+)
+
+const (
+	leafNodeFlag   byte = 0
+	branchNodeFlag byte = 1
+)
+
+// ErrInvalidNode is returned when a trie node in the DB cannot be decoded
+var ErrInvalidNode = errors.New("invalid trie node")
+
+// trieNode is a node of the state trie. It is either a leaf that holds the
+// Account of a key or a branch that holds the hashes of its two subtrees.
+type trieNode struct {
+	leaf    bool
+	key     common.Hash
+	account Account
+	left    common.Hash
+	right   common.Hash
+}
+
+// newLeaf returns a new leaf trieNode for the given key and Account
+func newLeaf(key common.Hash, account Account) *trieNode {
+	return &trieNode{leaf: true, key: key, account: account}
+}
+
+// newBranch returns a new branch trieNode for the given subtree hashes
+func newBranch(left, right common.Hash) *trieNode {
+	return &trieNode{left: left, right: right}
+}
+
+// encode returns the binary encoding of the trieNode. A leaf is encoded as its flag, key, balance, nonce,
+// stake and number of immature credits, followed by the amount and release height of each immature credit
+// and unbonding stake. A branch is encoded as its flag and the hashes of its subtrees.
+func (node *trieNode) encode() []byte {
+	if node.leaf {
+		account := node.account
+		data := make([]byte, leafNodeLength+creditLength*(len(account.Immature)+len(account.Unbonding)))
+		data[0] = leafNodeFlag
+		copy(data[1:], node.key[:])
+		binary.BigEndian.PutUint64(data[1+common.HashLength:], account.Balance)
+		binary.BigEndian.PutUint64(data[1+common.HashLength+8:], account.Nonce)
+		binary.BigEndian.PutUint64(data[1+common.HashLength+16:], account.Stake)
+		binary.BigEndian.PutUint32(data[1+common.HashLength+24:], uint32(len(account.Immature)))
+
+		offset := leafNodeLength
+		for _, credit := range account.Immature {
+			binary.BigEndian.PutUint64(data[offset:], credit.Amount)
+			binary.BigEndian.PutUint64(data[offset+8:], uint64(credit.Release))
+			offset += creditLength
+		}
+
+		for _, unbond := range account.Unbonding {
+			binary.BigEndian.PutUint64(data[offset:], unbond.Amount)
+			binary.BigEndian.PutUint64(data[offset+8:], uint64(unbond.Release))
+			offset += creditLength
+		}
+
+		return data
+	}
+
+	data := make([]byte, branchNodeLength)
+	data[0] = branchNodeFlag
+	copy(data[1:], node.left[:])
+	copy(data[1+common.HashLength:], node.right[:])
+
+	return data
+}
+
+// hash returns the hash of the trieNode's encoded representation
+func (node *trieNode) hash() common.Hash {
+	return common.Hash256(node.encode())
+}
+
+// decodeNode decodes the given data into a trieNode
+func decodeNode(data []byte) (*trieNode, error) {
+	switch {
+	case len(data) >= leafNodeLength && (len(data)-leafNodeLength)%creditLength == 0 && data[0] == leafNodeFlag:
+		account := Account{
+			Balance: binary.BigEndian.Uint64(data[1+common.HashLength:]),
+			Nonce:   binary.BigEndian.Uint64(data[1+common.HashLength+8:]),
+			Stake:   binary.BigEndian.Uint64(data[1+common.HashLength+16:]),
+		}
+
+		// The immature credits are followed by the unbonding stake
+		credits := int(binary.BigEndian.Uint32(data[1+common.HashLength+24:]))
+		if credits > (len(data)-leafNodeLength)/creditLength {
+			return nil, ErrInvalidNode
+		}
+
+		for offset := leafNodeLength; offset < len(data); offset += creditLength {
+			amount, release := binary.BigEndian.Uint64(data[offset:]), int64(binary.BigEndian.Uint64(data[offset+8:]))
+			if len(account.Immature) < credits {
+				account.Immature = append(account.Immature, ImmatureCredit{amount, release})
+			} else {
+				account.Unbonding = append(account.Unbonding, UnbondingStake{amount, release})
+			}
+		}
+
+		return newLeaf(common.BytesToHash(data[1:1+common.HashLength]), account), nil
+
+	case len(data) == branchNodeLength && data[0] == branchNodeFlag:
+		return newBranch(
+			common.BytesToHash(data[1:1+common.HashLength]),
+			common.BytesToHash(data[1+common.HashLength:]),
+		), nil
+
+	default:
+		return nil, ErrInvalidNode
+	}
+}
+
+// trieKey returns the key of the given address in the state trie
+func trieKey(address common.Address) common.Hash {
+	return common.Hash256([]byte(address))
+}
+
+// keyBit returns the bit of the key at the given depth, starting from the most significant bit
+func keyBit(key common.Hash, depth int) byte {
+	return (key[depth/8] >> (7 - depth%8)) & 1
+}
+
+// trie is a sparse Merkle tree of the accounts keyed by the hash of their address.
+//
+// The tree is compacted so that each leaf is stored at the shallowest depth at which it
+// is the only leaf of its subtree. The hash of an empty subtree is the null hash, the hash
+// of a subtree with a single leaf is the hash of the leaf and the hash of all other
+// subtrees is the hash of a branch node of their two children. The root of the trie
+// therefore only depends on the set of accounts and not on the order of their updates.
+//
+// Nodes are stored in the DB by their hash along with the height of the block that last
+// created them, and are never modified, so the trie at any previous root remains readable
+// until its nodes are pruned. Nodes created by updates are held in memory until they are
+// written into the DB with commit.
+type trie struct {
+	db    *db.Database
+	root  common.Hash
+	dirty map[common.Hash][]byte
+}
+
+// newTrie returns a trie with the given root on the given database
+func newTrie(database *db.Database, root common.Hash) *trie {
+	return &trie{database, root, make(map[common.Hash][]byte)}
+}
+
+// nodeKey returns the DB key for the trie node with the given hash
+func nodeKey(hash common.Hash) []byte {
+	return append(append([]byte{}, NodePrefix...), hash[:]...)
+}
+
+// load returns the trie node with the given hash
+func (t *trie) load(hash common.Hash) (*trieNode, error) {
+	if data, ok := t.dirty[hash]; ok {
+		return decodeNode(data)
+	}
+
+	data, _, err := readNode(t.db, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeNode(data)
+}
+
+// readNode reads the encoded trie node with the given hash and the height at which it was created from the DB
+func readNode(database *db.Database, hash common.Hash) ([]byte, int64, error) {
+	data, err := database.GetEntry(nodeKey(hash))
+	if err != nil {
+		return nil, 0, fmt.Errorf("trie node retrieve failed: %w", err)
+	}
+
+	if len(data) < 8 {
+		return nil, 0, ErrInvalidNode
+	}
+
+	split := len(data) - 8
+	return data[:split], int64(binary.BigEndian.Uint64(data[split:])), nil
+}
+
+// store adds the given trie node to the dirty nodes and returns its hash
+func (t *trie) store(node *trieNode) common.Hash {
+	data := node.encode()
+	hash := common.Hash256(data)
+	t.dirty[hash] = data
+
+	return hash
+}
+
+// get returns the Account for the given key. An empty Account is returned if the key does not exist.
+func (t *trie) get(key common.Hash) (*Account, error) {
+	hash := t.root
+
+	for depth := 0; depth < trieDepth; depth++ {
+		// Empty subtree, the key does not exist
+		if hash == common.NullHash() {
+			return new(Account), nil
+		}
+
+		node, err := t.load(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		// The leaf is the only key in the subtree
+		if node.leaf {
+			if node.key != key {
+				return new(Account), nil
+			}
+
+			account := node.account
+			return &account, nil
+		}
+
+		// Descend into the subtree of the key
+		if keyBit(key, depth) == 0 {
+			hash = node.left
+		} else {
+			hash = node.right
+		}
+	}
+
+	return nil, ErrInvalidNode
+}
+
+// update sets the Account for the given key and returns the new root.
+// An empty Account removes the key from the trie.
+func (t *trie) update(key common.Hash, account Account) (common.Hash, error) {
+	var err error
+	if account.Empty() {
+		t.root, err = t.remove(t.root, 0, key)
+	} else {
+		t.root, err = t.insert(t.root, 0, newLeaf(key, account))
+	}
+
+	return t.root, err
+}
+
+// insert inserts the given leaf into the subtree with the given hash at the given depth.
+// Returns the hash of the updated subtree.
+func (t *trie) insert(hash common.Hash, depth int, leaf *trieNode) (common.Hash, error) {
+	// The leaf becomes the only key in an empty subtree
+	if hash == common.NullHash() {
+		return t.store(leaf), nil
+	}
+
+	if depth >= trieDepth {
+		return common.NullHash(), ErrInvalidNode
+	}
+
+	node, err := t.load(hash)
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	if node.leaf {
+		// Replace the leaf of the same key
+		if node.key == leaf.key {
+			return t.store(leaf), nil
+		}
+
+		// Split the subtree between the existing and the new leaf
+		return t.split(depth, node, leaf), nil
+	}
+
+	// Insert into the subtree of the key
+	left, right := node.left, node.right
+	if keyBit(leaf.key, depth) == 0 {
+		left, err = t.insert(left, depth+1, leaf)
+	} else {
+		right, err = t.insert(right, depth+1, leaf)
+	}
+
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	return t.store(newBranch(left, right)), nil
+}
+
+// split returns the hash of a subtree at the given depth that contains the two given leaves.
+// Branches are added until the depth at which the keys of the leaves differ.
+func (t *trie) split(depth int, a, b *trieNode) common.Hash {
+	abit, bbit := keyBit(a.key, depth), keyBit(b.key, depth)
+
+	// Both leaves are in the same subtree
+	if abit == bbit {
+		child := t.split(depth+1, a, b)
+		if abit == 0 {
+			return t.store(newBranch(child, common.NullHash()))
+		}
+
+		return t.store(newBranch(common.NullHash(), child))
+	}
+
+	// The leaves are in different subtrees
+	ahash, bhash := t.store(a), t.store(b)
+	if abit == 0 {
+		return t.store(newBranch(ahash, bhash))
+	}
+
+	return t.store(newBranch(bhash, ahash))
+}
+
+// remove removes the given key from the subtree with the given hash at the given depth.
+// Returns the hash of the updated subtree, which is collapsed if it is left with a single leaf.
+func (t *trie) remove(hash common.Hash, depth int, key common.Hash) (common.Hash, error) {
+	// The key does not exist in an empty subtree
+	if hash == common.NullHash() {
+		return hash, nil
+	}
+
+	if depth >= trieDepth {
+		return common.NullHash(), ErrInvalidNode
+	}
+
+	node, err := t.load(hash)
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	if node.leaf {
+		// Remove the leaf of the key
+		if node.key == key {
+			return common.NullHash(), nil
+		}
+
+		// The key does not exist in the subtree
+		return hash, nil
+	}
+
+	// Remove from the subtree of the key
+	left, right := node.left, node.right
+	if keyBit(key, depth) == 0 {
+		left, err = t.remove(left, depth+1, key)
+	} else {
+		right, err = t.remove(right, depth+1, key)
+	}
+
+	if err != nil {
+		return common.NullHash(), err
+	}
+
+	// Collapse the subtree if it has no keys or only a single leaf
+	switch {
+	case left == common.NullHash() && right == common.NullHash():
+		return common.NullHash(), nil
+
+	case left == common.NullHash() || right == common.NullHash():
+		remaining := left
+		if remaining == common.NullHash() {
+			remaining = right
+		}
+
+		child, err := t.load(remaining)
+		if err != nil {
+			return common.NullHash(), err
+		}
+
+		if child.leaf {
+			return remaining, nil
+		}
+	}
+
+	return t.store(newBranch(left, right)), nil
+}
+
+// writtenNode represents a trie node written into the DB by a commit, along with the height
+// at which it was created before the commit if it was already in the DB
+type writtenNode struct {
+	Hash    common.Hash
+	Existed bool
+	Created int64
+}
+
+// commit writes the dirty nodes that are part of the trie into the DB as created at the given height.
+// Dirty nodes that were replaced by later updates are discarded. Returns the written nodes and
+// the hashes of the nodes of the trie at the given prior root that are not part of the committed trie.
+func (t *trie) commit(prior common.Hash, height int64) ([]writtenNode, []common.Hash, error) {
+	var suffix [8]byte
+	binary.BigEndian.PutUint64(suffix[:], uint64(height))
+
+	// Collect the nodes of the trie, which are the dirty nodes reachable from the root
+	// and the nodes in the DB that they reference. Only the dirty nodes are written.
+	current := make(map[common.Hash]struct{})
+	written := make([]writtenNode, 0, len(t.dirty))
+	pending := []common.Hash{t.root}
+
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if hash == common.NullHash() {
+			continue
+		}
+
+		current[hash] = struct{}{}
+
+		data, ok := t.dirty[hash]
+		if !ok {
+			continue
+		}
+
+		// Record whether the node already existed, so that the commit can be reverted
+		_, created, err := readNode(t.db, hash)
+		switch {
+		case err == nil:
+			written = append(written, writtenNode{hash, true, created})
+		case errors.Is(err, db.ErrKeyNotFound):
+			written = append(written, writtenNode{Hash: hash})
+		default:
+			return nil, nil, err
+		}
+
+		if err := t.db.SetEntry(nodeKey(hash), append(append([]byte{}, data...), suffix[:]...)); err != nil {
+			return nil, nil, fmt.Errorf("trie node store to db failed: %w", err)
+		}
+
+		node, err := decodeNode(data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !node.leaf {
+			pending = append(pending, node.left, node.right)
+		}
+	}
+
+	// Collect the nodes of the prior trie that have been replaced. The subtrees
+	// of nodes that are part of the committed trie are shared between them.
+	replaced := make([]common.Hash, 0)
+	pending = []common.Hash{prior}
+
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if hash == common.NullHash() {
+			continue
+		}
+
+		if _, ok := current[hash]; ok {
+			continue
+		}
+
+		replaced = append(replaced, hash)
+
+		data, _, err := readNode(t.db, hash)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		node, err := decodeNode(data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !node.leaf {
+			pending = append(pending, node.left, node.right)
+		}
+	}
+
+	t.dirty = make(map[common.Hash][]byte)
+	return written, replaced, nil
+}
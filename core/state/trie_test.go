@@ -0,0 +1,161 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/db"
+)
+
+// testAccounts returns the given number of addresses, each with an Account whose balance is its index plus 1
+func testAccounts(count int) ([]common.Address, map[common.Address]Account) {
+	addresses := make([]common.Address, count)
+	accounts := make(map[common.Address]Account, count)
+
+	for i := range addresses {
+		addresses[i] = common.Address(fmt.Sprintf("account-%v", i))
+		accounts[addresses[i]] = Account{Balance: uint64(i + 1)}
+	}
+
+	return addresses, accounts
+}
+
+// buildTrie returns the root of a trie with the Accounts of the given addresses inserted in order
+func buildTrie(t *testing.T, addresses []common.Address, accounts map[common.Address]Account) (*trie, common.Hash) {
+	t.Helper()
+
+	tr := newTrie(db.OpenMemory(), common.NullHash())
+	for _, address := range addresses {
+		if _, err := tr.update(trieKey(address), accounts[address]); err != nil {
+			t.Fatalf("failed to update trie: %v", err)
+		}
+	}
+
+	return tr, tr.root
+}
+
+func TestTrieRoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		removed []int
+	}{
+		{"single account", 1, nil},
+		{"two accounts", 2, nil},
+		{"many accounts", 64, nil},
+		{"remove the only account", 1, []int{0}},
+		{"remove one of two accounts", 2, []int{1}},
+		{"remove accounts", 64, []int{0, 7, 31, 32, 63}},
+		{"remove all accounts", 8, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+	}
+
+	for _, test := range tests {
+		addresses, accounts := testAccounts(test.count)
+
+		// The root must not depend on the order of the updates
+		reversed := make([]common.Address, len(addresses))
+		for i, address := range addresses {
+			reversed[len(addresses)-1-i] = address
+		}
+
+		forward, root := buildTrie(t, addresses, accounts)
+		if _, got := buildTrie(t, reversed, accounts); got != root {
+			t.Errorf("%v: root %v in reverse order, want %v", test.name, got.Hex(), root.Hex())
+		}
+
+		// Removing accounts must compact the trie into the trie without them
+		remaining := make(map[common.Address]Account, len(accounts))
+		for address, account := range accounts {
+			remaining[address] = account
+		}
+
+		for _, index := range test.removed {
+			if _, err := forward.update(trieKey(addresses[index]), Account{}); err != nil {
+				t.Fatalf("%v: failed to remove account: %v", test.name, err)
+			}
+
+			delete(remaining, addresses[index])
+		}
+
+		var kept []common.Address
+		for _, address := range addresses {
+			if _, ok := remaining[address]; ok {
+				kept = append(kept, address)
+			}
+		}
+
+		if _, want := buildTrie(t, kept, remaining); forward.root != want {
+			t.Errorf("%v: root %v after removal, want %v", test.name, forward.root.Hex(), want.Hex())
+		}
+
+		// Every account must be readable and the removed accounts must be empty
+		for _, address := range addresses {
+			account, err := forward.get(trieKey(address))
+			if err != nil {
+				t.Fatalf("%v: failed to get account: %v", test.name, err)
+			}
+
+			if want := remaining[address]; !reflect.DeepEqual(*account, want) {
+				t.Errorf("%v: account %v is %+v, want %+v", test.name, address, *account, want)
+			}
+		}
+	}
+}
+
+func TestTrieNodeEncoding(t *testing.T) {
+	key := common.Hash256([]byte("key"))
+
+	tests := []struct {
+		name string
+		node *trieNode
+	}{
+		{"leaf", newLeaf(key, Account{Balance: 1, Nonce: 2})},
+		{"leaf with stake", newLeaf(key, Account{Balance: 1, Stake: 3})},
+		{"leaf with immature credits", newLeaf(key, Account{Balance: 5, Immature: []ImmatureCredit{{1, 10}, {2, 11}}})},
+		{"leaf with unbonding stake", newLeaf(key, Account{Nonce: 1, Unbonding: []UnbondingStake{{3, 12}}})},
+		{"leaf with credits and unbonding stake", newLeaf(key, Account{
+			Balance: 5, Immature: []ImmatureCredit{{1, 10}}, Unbonding: []UnbondingStake{{3, 12}, {4, 13}},
+		})},
+		{"branch", newBranch(key, common.Hash256([]byte("right")))},
+		{"branch with an empty subtree", newBranch(common.NullHash(), key)},
+	}
+
+	for _, test := range tests {
+		node, err := decodeNode(test.node.encode())
+		if err != nil {
+			t.Errorf("%v: failed to decode: %v", test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(node, test.node) {
+			t.Errorf("%v: decoded %+v, want %+v", test.name, node, test.node)
+		}
+	}
+
+	leaf := newLeaf(key, Account{Balance: 1, Immature: []ImmatureCredit{{1, 10}}}).encode()
+	branch := newBranch(key, key).encode()
+
+	// tooManyCredits is a leaf with more immature credits than its encoded credits
+	tooManyCredits := append([]byte{}, leaf...)
+	tooManyCredits[1+common.HashLength+27] = 2
+
+	invalid := [][]byte{
+		nil,
+		leaf[:len(leaf)-1],
+		leaf[:leafNodeLength-1],
+		append([]byte{branchNodeFlag}, leaf[1:]...),
+		tooManyCredits,
+		branch[:len(branch)-1],
+		append([]byte{leafNodeFlag}, branch[1:]...),
+		append([]byte{2}, branch[1:]...),
+	}
+
+	for _, data := range invalid {
+		if _, err := decodeNode(data); !errors.Is(err, ErrInvalidNode) {
+			t.Errorf("decodeNode(%x): got error %v, want %v", data, err, ErrInvalidNode)
+		}
+	}
+}
@@ -2,11 +2,20 @@ package core
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"math"
 
 	"github.com/manishmeganathan/essensio/common"
 )
 
+var (
+	// ErrFeeOverflow is returned when the block reward and the fees of a block overflow the coinbase value
+	ErrFeeOverflow = errors.New("transaction fees overflow")
+	// ErrInvalidChainID is returned for a transaction whose chain ID is not the chain ID of the chain
+	ErrInvalidChainID = errors.New("transaction chain id does not match the chain")
+)
+
 // Transactions is a group of Transaction objects
 type Transactions []*Transaction
 
@@ -14,8 +23,13 @@ type Transactions []*Transaction
 // It contains a nonce value to make it unique for transactions
 // between the same account with the same value.
 type Transaction struct {
+	// Represents the chain ID of the network on which the transaction is valid
+	ChainID uint64
+
 	// Represents the amount of tokens transferred in Nubs
 	Value uint64
+	// Represents the fee paid to the block miner in Nubs
+	Fee uint64
 	// Represents the sender account nonce
 	Nonce uint64
 
@@ -25,15 +39,44 @@ type Transaction struct {
 	To common.Address
 }
 
-// NewTransaction generates a new Transaction between from and to for the given value and nonce.
-func NewTransaction(from, to common.Address, nonce, value uint64) *Transaction {
-	return &Transaction{value, nonce, from, to}
+// NewTransaction generates a new Transaction between from and to for the given value,
+// fee and nonce that is valid on the network with the given chain ID.
+func NewTransaction(chainID uint64, from, to common.Address, nonce, value, fee uint64) *Transaction {
+	return &Transaction{chainID, value, fee, nonce, from, to}
+}
+
+// NewCoinbaseTransaction generates a new coinbase transaction that mints tokens for the given address.
+// The value of the transaction is the given block reward plus the fees of the given
+// transactions of the block, as returned by CoinbaseValue.
+func NewCoinbaseTransaction(chainID uint64, address common.Address, reward uint64, txns Transactions) (*Transaction, error) {
+	value, err := CoinbaseValue(reward, txns)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMintTransaction(chainID, address, value), nil
 }
 
-// newCoinbaseTransaction generates a new coinbase transaction that mints tokens for the given address.
-// The value of the transaction is the default Block Reward for mining a block.
-func newCoinbaseTransaction(address common.Address) *Transaction {
-	return &Transaction{BlockReward, 0, address, common.NullAddress()}
+// NewMintTransaction generates a new transaction that mints the given value of tokens
+// for the given address on the network with the given chain ID.
+func NewMintTransaction(chainID uint64, address common.Address, value uint64) *Transaction {
+	return &Transaction{chainID, value, 0, 0, address, common.NullAddress()}
+}
+
+// CoinbaseValue returns the value of the coinbase transaction for a block with the given reward and
+// transactions. The value is the block reward plus the sum of the fees of the transactions.
+// Returns ErrFeeOverflow if the value does not fit in a uint64.
+func CoinbaseValue(reward uint64, txns Transactions) (uint64, error) {
+	value := reward
+	for _, txn := range txns {
+		if value > math.MaxUint64-txn.Fee {
+			return 0, ErrFeeOverflow
+		}
+
+		value += txn.Fee
+	}
+
+	return value, nil
 }
 
 // Serialize implements the common.Serializable interface for Transaction.
@@ -58,6 +101,7 @@ func (txn *Transaction) Deserialize(data []byte) error {
 }
 
 // Hash returns the SHA-256	hash of the Transaction's serialized representation.
+// The hash commits to the chain ID, so a transaction is never the same on two networks.
 func (txn *Transaction) Hash() common.Hash {
 	data, err := txn.Serialize()
 	if err != nil {
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCoinbaseValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		reward uint64
+		fees   []uint64
+		want   uint64
+		err    error
+	}{
+		{"reward only", BlockReward, nil, BlockReward, nil},
+		{"reward and fees", BlockReward, []uint64{1, 2, 3}, BlockReward + 6, nil},
+		{"fees without reward", 0, []uint64{5, 0}, 5, nil},
+		{"maximum value", math.MaxUint64 - 1, []uint64{1}, math.MaxUint64, nil},
+		{"fee overflow", math.MaxUint64, []uint64{1}, 0, ErrFeeOverflow},
+		{"fees overflow", 1, []uint64{math.MaxUint64 - 1, 1}, 0, ErrFeeOverflow},
+	}
+
+	for _, test := range tests {
+		txns := make(Transactions, 0, len(test.fees))
+		for nonce, fee := range test.fees {
+			txns = append(txns, NewTransaction(DefaultChainID, "sender", "receiver", uint64(nonce), 1, fee))
+		}
+
+		value, err := CoinbaseValue(test.reward, txns)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.err)
+			continue
+		}
+
+		if value != test.want {
+			t.Errorf("%v: value %v, want %v", test.name, value, test.want)
+		}
+
+		// The coinbase transaction mints the value for the address without a fee
+		if coinbase, err := NewCoinbaseTransaction(DefaultChainID, "miner", test.reward, txns); err == nil &&
+			(coinbase.Value != test.want || coinbase.Fee != 0 || coinbase.From != "miner") {
+			t.Errorf("%v: coinbase transaction %+v, want a mint of %v", test.name, coinbase, test.want)
+		}
+	}
+}
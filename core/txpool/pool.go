@@ -1,6 +1,8 @@
 package txpool
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/manishmeganathan/essensio/common"
@@ -20,8 +22,13 @@ type TxnPool interface {
 	// They are not removed from the pool until Clear is called with the Transaction
 	FetchFor(common.Address) *TransactionSet
 
-	// Insert inserts Transactions into the pool
-	Insert(...*core.Transaction)
+	// Insert inserts Transactions into the pool.
+	// Returns an error without inserting any of them if a Transaction is not for the chain of the pool.
+	Insert(...*core.Transaction) error
+	// Senders returns the addresses that have Transactions in the active set
+	Senders() []common.Address
+	// Transactions returns all the Transactions in the active and pending sets without fetching them
+	Transactions() core.Transactions
 	// Contains returns whether a transaction exists for a given transaction hash.
 	// Will return true only if the transaction exists in the active set.
 	Contains(common.Hash) bool
@@ -48,6 +55,9 @@ type TxnNoncePool struct {
 	// thread safety mutex
 	mu *sync.RWMutex
 
+	// chainID is the chain ID of the Transactions accepted by the pool
+	chainID uint64
+
 	// pool is the collection of Transactions in the TxnNoncePool
 	// grouped by address and indexed by nonce
 	pool map[common.Address]*TransactionSet
@@ -62,9 +72,11 @@ type TxnNoncePool struct {
 }
 
 // NewTxnNoncePool generates and returns a new TxnNoncePool object
-func NewTxnNoncePool() *TxnNoncePool {
+// that accepts Transactions with the given chain ID
+func NewTxnNoncePool(chainID uint64) *TxnNoncePool {
 	return &TxnNoncePool{
 		mu:      &sync.RWMutex{},
+		chainID: chainID,
 		pool:    make(map[common.Address]*TransactionSet),
 		pending: make(map[common.Hash]*core.Transaction),
 		lookup:  make(map[common.Hash]*core.Transaction),
@@ -115,6 +127,9 @@ func (pool *TxnNoncePool) FetchFor(address common.Address) *TransactionSet {
 
 	// Get transaction for Address from Pool
 	txset := pool.pool[address]
+	if txset == nil {
+		return nil
+	}
 
 	// Flatten the transaction set and iterate through it
 	for _, txn := range txset.Flatten() {
@@ -134,7 +149,15 @@ func (pool *TxnNoncePool) FetchFor(address common.Address) *TransactionSet {
 
 // Insert implements the TxnPool interface for TxnNoncePool.
 // Accepts a variadic number of Transactions and adds each one to the active set.
-func (pool *TxnNoncePool) Insert(transactions ...*core.Transaction) {
+// None of the Transactions are inserted if any of them has a chain ID other than that of the pool.
+func (pool *TxnNoncePool) Insert(transactions ...*core.Transaction) error {
+	// Reject transactions for other networks
+	for _, txn := range transactions {
+		if txn.ChainID != pool.chainID {
+			return fmt.Errorf("transaction %v: %w", txn.Hash().Hex(), core.ErrInvalidChainID)
+		}
+	}
+
 	// Acquire Mutex
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
@@ -154,6 +177,57 @@ func (pool *TxnNoncePool) Insert(transactions ...*core.Transaction) {
 		// Add the transaction into the lookup
 		pool.lookup[txn.Hash()] = txn
 	}
+
+	return nil
+}
+
+// Senders implements the TxnPool interface for TxnNoncePool.
+// Returns the addresses with Transactions in the active set, sorted in ascending order.
+func (pool *TxnNoncePool) Senders() []common.Address {
+	// Acquire RLock
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	senders := make([]common.Address, 0, len(pool.pool))
+	for address, txset := range pool.pool {
+		// Skip addresses whose transactions have all been fetched
+		if txset.Len() == 0 {
+			continue
+		}
+
+		senders = append(senders, address)
+	}
+
+	sort.Slice(senders, func(i, j int) bool { return senders[i] < senders[j] })
+	return senders
+}
+
+// Transactions implements the TxnPool interface for TxnNoncePool.
+// Returns the Transactions in the active and pending sets, sorted by sender and nonce.
+// The transactions remain in their sets.
+func (pool *TxnNoncePool) Transactions() core.Transactions {
+	// Acquire RLock
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	transactions := make(core.Transactions, 0, len(pool.lookup)+len(pool.pending))
+	for _, txn := range pool.lookup {
+		transactions = append(transactions, txn)
+	}
+
+	for _, txn := range pool.pending {
+		transactions = append(transactions, txn)
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].From != transactions[j].From {
+			return transactions[i].From < transactions[j].From
+		}
+
+		return transactions[i].Nonce < transactions[j].Nonce
+	})
+
+	return transactions
 }
 
 // Contains implements the TxnPool interface for TxnNoncePool.
@@ -233,8 +307,9 @@ func (pool *TxnNoncePool) Purge() int {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	// Collect total number of transactions in the pool
-	count := pool.Pending() + pool.Active()
+	// Collect total number of transactions in the pool.
+	// The counts are read directly since the mutex is already held.
+	count := len(pool.pending) + len(pool.lookup)
 
 	pool.pool = make(map[common.Address]*TransactionSet)
 	pool.pending = make(map[common.Hash]*core.Transaction)
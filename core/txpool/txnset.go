@@ -23,6 +23,11 @@ func NewTransactionSet() *TransactionSet {
 	}
 }
 
+// Len returns the number of Transactions in the set
+func (txset *TransactionSet) Len() int {
+	return len(txset.items)
+}
+
 // Flatten returns a flat slice of nonce-sorted transactions as core.Transactions.
 func (txset *TransactionSet) Flatten() core.Transactions {
 	// Create a slice in which to collect transactions
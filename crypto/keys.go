@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+const (
+	// PublicKeyLength is the length of a public key in bytes
+	PublicKeyLength = ed25519.PublicKeySize
+	// SignatureLength is the length of a signature in bytes
+	SignatureLength = ed25519.SignatureSize
+	// SeedLength is the length of a private key seed in bytes
+	SeedLength = ed25519.SeedSize
+)
+
+// PrivateKey represents an Ed25519 private key that can sign data
+type PrivateKey = ed25519.PrivateKey
+
+// PublicKey represents an Ed25519 public key that can verify signatures
+type PublicKey = ed25519.PublicKey
+
+// GenerateKey generates and returns a new random PrivateKey
+func GenerateKey() (PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("key generation failed: %w", err)
+	}
+
+	return key, nil
+}
+
+// KeyFromSeed returns the PrivateKey for the given 32 byte seed
+func KeyFromSeed(seed []byte) (PrivateKey, error) {
+	if len(seed) != SeedLength {
+		return nil, fmt.Errorf("invalid seed length: %v", len(seed))
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// KeyFromHex returns the PrivateKey for the given hex encoded 32 byte seed
+func KeyFromHex(input string) (PrivateKey, error) {
+	seed, err := common.HexDecode(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+
+	return KeyFromSeed(seed)
+}
+
+// KeyToHex returns the hex encoded seed of the given PrivateKey
+func KeyToHex(key PrivateKey) string {
+	return common.HexEncode(key.Seed())
+}
+
+// PubkeyToAddress returns the Address for the given PublicKey.
+// The Address is the hex encoding of the last 20 bytes of the hash of the key.
+func PubkeyToAddress(pubkey PublicKey) common.Address {
+	hash := common.Hash256(pubkey)
+	return common.Address(common.HexEncode(hash[common.HashLength-common.AddressLength:]))
+}
+
+// KeyToAddress returns the Address for the public key of the given PrivateKey
+func KeyToAddress(key PrivateKey) common.Address {
+	return PubkeyToAddress(key.Public().(PublicKey))
+}
+
+// Sign signs the given hash with the PrivateKey and returns the signature
+// prefixed with the public key, so that the signer can be recovered from it.
+func Sign(key PrivateKey, hash common.Hash) []byte {
+	signature := ed25519.Sign(key, hash.Bytes())
+	return append(append([]byte{}, key.Public().(PublicKey)...), signature...)
+}
+
+// Recover verifies a signature generated by Sign for the given
+// hash and returns the Address of the key that generated it.
+func Recover(hash common.Hash, signature []byte) (common.Address, error) {
+	if len(signature) != PublicKeyLength+SignatureLength {
+		return common.NullAddress(), fmt.Errorf("invalid signature length: %v", len(signature))
+	}
+
+	// Split the public key and the signature
+	pubkey := PublicKey(signature[:PublicKeyLength])
+	if !ed25519.Verify(pubkey, hash.Bytes(), signature[PublicKeyLength:]) {
+		return common.NullAddress(), fmt.Errorf("signature verification failed")
+	}
+
+	return PubkeyToAddress(pubkey), nil
+}
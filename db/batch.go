@@ -0,0 +1,136 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger"
+)
+
+// ErrNotBatch is returned when a Database that is not a batch is committed
+var ErrNotBatch = errors.New("database is not a batch")
+
+// batchWrite is a pending write of a batch. The key is removed if deleted is set.
+type batchWrite struct {
+	value   []byte
+	deleted bool
+}
+
+// batchStore is a thread safe set of pending writes to a parent Database.
+// It is used as the backend of batches, whose reads include their pending writes.
+type batchStore struct {
+	mu     sync.RWMutex
+	parent *Database
+	writes map[string]batchWrite
+}
+
+// NewBatch returns a new batch on the Database. A batch is a Database whose writes are
+// held in memory until they are written into the Database together with Commit.
+// Reads from the batch include its pending writes. A batch that is not committed is discarded.
+func (db *Database) NewBatch() *Database {
+	return &Database{batch: &batchStore{parent: db, writes: make(map[string]batchWrite)}}
+}
+
+// Commit writes all the pending writes of a batch into its parent Database atomically,
+// either all of them are written or none of them are. The batch is empty after it is committed.
+// Returns ErrNotBatch if the Database is not a batch.
+func (db *Database) Commit() error {
+	if db.batch == nil {
+		return ErrNotBatch
+	}
+
+	return db.batch.commit()
+}
+
+// get returns a copy of the value for the given key,
+// from the pending writes or otherwise from the parent
+func (store *batchStore) get(key []byte) ([]byte, error) {
+	store.mu.RLock()
+	write, ok := store.writes[string(key)]
+	store.mu.RUnlock()
+
+	if !ok {
+		return store.parent.GetEntry(key)
+	}
+
+	if write.deleted {
+		return nil, ErrKeyNotFound
+	}
+
+	return append([]byte{}, write.value...), nil
+}
+
+// set adds a pending write of a copy of the value for the given key
+func (store *batchStore) set(key, value []byte) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.writes[string(key)] = batchWrite{value: append([]byte{}, value...)}
+}
+
+// delete adds a pending removal of the value for the given key
+func (store *batchStore) delete(key []byte) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.writes[string(key)] = batchWrite{deleted: true}
+}
+
+// reset discards all the pending writes of the store
+func (store *batchStore) reset() {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.writes = make(map[string]batchWrite)
+}
+
+// commit writes the pending writes into the parent atomically and discards them
+func (store *batchStore) commit() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var err error
+	switch parent := store.parent; {
+	case parent.memory != nil:
+		parent.memory.apply(store.writes)
+	case parent.batch != nil:
+		parent.batch.apply(store.writes)
+	default:
+		// Write all the pending writes in a single Badger transaction
+		err = parent.client.Update(func(txn *badger.Txn) error {
+			for key, write := range store.writes {
+				if write.deleted {
+					if err := txn.Delete([]byte(key)); err != nil {
+						return fmt.Errorf("db delete for key '%x' failed: %w", key, err)
+					}
+
+					continue
+				}
+
+				if err := txn.Set([]byte(key), write.value); err != nil {
+					return fmt.Errorf("db set for key '%x' failed: %w", key, err)
+				}
+			}
+
+			return nil
+		})
+	}
+
+	if err != nil {
+		return fmt.Errorf("db batch commit failed: %w", err)
+	}
+
+	store.writes = make(map[string]batchWrite)
+	return nil
+}
+
+// apply adds the given writes of a child batch to the pending writes
+func (store *batchStore) apply(writes map[string]batchWrite) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for key, write := range writes {
+		store.writes[key] = write
+	}
+}
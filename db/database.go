@@ -2,18 +2,32 @@ package db
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/dgraph-io/badger"
 )
 
+// ErrKeyNotFound is returned when a key does not exist in the Database
+var ErrKeyNotFound = badger.ErrKeyNotFound
+
+// Database is a key-value store for blockchain data.
+// It is backed by a Badger client or, for throwaway databases, by an in-memory store.
+// A batch is backed by the pending writes to another Database.
 type Database struct {
 	client *badger.DB
+	memory *memoryStore
+	batch  *batchStore
 }
 
-// Open opens a Badger client to the database at Dir()
-func Open() (*Database, error) {
+// Open opens a Badger client to the database at the given directory, such as a Dir()
+func Open(dir string) (*Database, error) {
+	// Create the database directory along with the data root
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("db directory create fail: %w", err)
+	}
+
 	// Setup Badger Options
-	opts := badger.DefaultOptions(Dir())
+	opts := badger.DefaultOptions(dir)
 	opts.Logger = nil
 
 	// Open Badger Client
@@ -23,17 +37,46 @@ func Open() (*Database, error) {
 	}
 
 	// Wrap client inside Database and return
-	return &Database{client}, nil
+	return &Database{client: client}, nil
 }
 
-// Close closes the Badger client to the database at Dir()
+// Close closes the Badger client to the database.
+// The contents of an in-memory database and the pending writes of a batch are discarded.
 func (db *Database) Close() {
+	if db.batch != nil {
+		db.batch.reset()
+		return
+	}
+
+	if db.memory != nil {
+		db.memory.reset()
+		return
+	}
+
 	if err := db.client.Close(); err != nil {
 		panic(fmt.Errorf("db close fail: %w", err))
 	}
 }
 
+// Has returns whether an entry exists for the given key
+func (db *Database) Has(key []byte) bool {
+	_, err := db.GetEntry(key)
+	return err == nil
+}
+
 func (db *Database) GetEntry(key []byte) (value []byte, err error) {
+	if db.batch != nil {
+		return db.batch.get(key)
+	}
+
+	if db.memory != nil {
+		if value, err = db.memory.get(key); err != nil {
+			return nil, fmt.Errorf("db get on key '%x' fail: %w", key, err)
+		}
+
+		return value, nil
+	}
+
 	// Define a view transaction on the database
 	err = db.client.View(func(txn *badger.Txn) error {
 		// Attempt to get the Item for the given key
@@ -44,7 +87,7 @@ func (db *Database) GetEntry(key []byte) (value []byte, err error) {
 
 		// Retrieve the value from the Item
 		if err = item.Value(func(val []byte) error {
-			value = val
+			value = append([]byte{}, val...)
 			return nil
 
 		}); err != nil {
@@ -58,6 +101,16 @@ func (db *Database) GetEntry(key []byte) (value []byte, err error) {
 }
 
 func (db *Database) SetEntry(key, value []byte) error {
+	if db.batch != nil {
+		db.batch.set(key, value)
+		return nil
+	}
+
+	if db.memory != nil {
+		db.memory.set(key, value)
+		return nil
+	}
+
 	// Define an update transaction the database
 	return db.client.Update(func(txn *badger.Txn) error {
 		// Attempt to set the key-value pair to the database
@@ -68,3 +121,25 @@ func (db *Database) SetEntry(key, value []byte) error {
 		return nil
 	})
 }
+
+func (db *Database) DeleteEntry(key []byte) error {
+	if db.batch != nil {
+		db.batch.delete(key)
+		return nil
+	}
+
+	if db.memory != nil {
+		db.memory.delete(key)
+		return nil
+	}
+
+	// Define an update transaction the database
+	return db.client.Update(func(txn *badger.Txn) error {
+		// Attempt to delete the key from the database
+		if err := txn.Delete(key); err != nil {
+			return fmt.Errorf("db delete for key '%x' failed: %w", key, err)
+		}
+
+		return nil
+	})
+}
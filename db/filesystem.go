@@ -9,11 +9,11 @@ import (
 
 const dbFolder = "data"
 
-// Exists returns a boolean indicating if the database directory is already initialized
-func Exists() bool {
+// Exists returns a boolean indicating if the database directory at the given path is already initialized
+func Exists(dir string) bool {
 	// Create path to MANIFEST file in database directory.
 	// This MANIFEST file is good indication of whether the database is initialized
-	manifest := filepath.Join(Dir(), "MANIFEST")
+	manifest := filepath.Join(dir, "MANIFEST")
 
 	// Check if the MANIFEST file exists
 	if _, err := os.Stat(manifest); errors.Is(err, os.ErrNotExist) {
@@ -24,9 +24,15 @@ func Exists() bool {
 	return true
 }
 
-// Dir returns the path to the directory that contains the database contents.
+// Dir returns the path to the directory that contains the database contents
+// of the given network, which is a directory named after it in the given data root.
+func Dir(root, network string) string {
+	return filepath.Join(root, network)
+}
+
+// DefaultRoot returns the path to the default data root.
 // It is always in the same directory as the running binary.
-func Dir() string {
+func DefaultRoot() string {
 	// Get path to executable
 	executable, err := os.Executable()
 	if err != nil {
@@ -35,6 +41,6 @@ func Dir() string {
 
 	// Get directory of the executable
 	execDir := filepath.Dir(executable)
-	// Add dbFolder to return directory of database
+	// Add dbFolder to return the data root
 	return filepath.Join(execDir, dbFolder)
 }
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"sync"
+)
+
+// memoryStore is a thread safe in-memory key-value store.
+// It is used as the backend of throwaway databases that are never persisted.
+type memoryStore struct {
+	mu      sync.RWMutex
+	entries map[string][]byte
+}
+
+// OpenMemory opens a new empty Database that is held entirely in memory.
+// All its contents are discarded when it is closed.
+func OpenMemory() *Database {
+	return &Database{memory: &memoryStore{entries: make(map[string][]byte)}}
+}
+
+// get returns a copy of the value for the given key or ErrKeyNotFound
+func (store *memoryStore) get(key []byte) ([]byte, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	value, ok := store.entries[string(key)]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return append([]byte{}, value...), nil
+}
+
+// set stores a copy of the value for the given key
+func (store *memoryStore) set(key, value []byte) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.entries[string(key)] = append([]byte{}, value...)
+}
+
+// delete removes the value for the given key
+func (store *memoryStore) delete(key []byte) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.entries, string(key))
+}
+
+// apply applies the given writes of a batch to the store at once
+func (store *memoryStore) apply(writes map[string]batchWrite) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for key, write := range writes {
+		if write.deleted {
+			delete(store.entries, key)
+			continue
+		}
+
+		store.entries[key] = write.value
+	}
+}
+
+// reset discards all the entries of the store
+func (store *memoryStore) reset() {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.entries = make(map[string][]byte)
+}
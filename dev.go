@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/crypto"
+)
+
+// devAccountBalance is the initial balance of each development account
+const devAccountBalance = 1000 * common.Essence
+
+// devGenesis returns the Genesis of a development chain on the given network sealed by the instant
+// engine with the given block period, along with the keys of the given number of prefunded
+// accounts. The keys are derived deterministically so that they are the same on every run.
+func devGenesis(network *core.Network, accounts int, period int64) (*core.Genesis, []crypto.PrivateKey) {
+	if accounts < 1 {
+		accounts = 1
+	}
+
+	genesis := network.Genesis()
+	genesis.Config.Engine = core.EngineInstant
+	genesis.Config.Instant = &core.InstantConfig{Period: period}
+	genesis.Alloc = make(core.GenesisAlloc, accounts)
+
+	keys := make([]crypto.PrivateKey, 0, accounts)
+
+	fmt.Println(">>>> Development Chain. Prefunded Accounts <<<<")
+	for i := 0; i < accounts; i++ {
+		// Derive the key from the hash of the account index
+		seed := common.Hash256([]byte(fmt.Sprintf("essensio-dev-account-%v", i)))
+		key, err := crypto.KeyFromSeed(seed.Bytes())
+		if err != nil {
+			panic(fmt.Errorf("development key derivation failed: %w", err))
+		}
+
+		address := crypto.KeyToAddress(key)
+		genesis.Alloc[address] = devAccountBalance
+		keys = append(keys, key)
+
+		// The first account receives the block rewards, starting from the genesis block
+		if i == 0 {
+			genesis.Coinbase = address
+		}
+
+		fmt.Printf("(%v) %v [Key: %v]\n", i, address, crypto.KeyToHex(key))
+	}
+
+	return genesis, keys
+}
@@ -0,0 +1,251 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core/state"
+)
+
+// AccountArgs are the arguments of the account state methods. The state is read
+// at the block with the given hash or height, or at the chain head if neither is set.
+// Pending also reports the account after the transactions in the pool, only at the chain head.
+type AccountArgs struct {
+	Address   string `json:"address"`
+	Height    *int64 `json:"height,omitempty"`
+	BlockHash string `json:"block_hash,omitempty"`
+	Pending   bool   `json:"pending,omitempty"`
+}
+
+type GetBalanceResult struct {
+	Address        string            `json:"address"`
+	Balance        uint64            `json:"balance"`
+	Spendable      uint64            `json:"spendable"`
+	Denominations  map[string]string `json:"denominations"`
+	PendingBalance *uint64           `json:"pending_balance,omitempty"`
+	BlockHeight    uint64            `json:"block_height"`
+}
+
+func (api *API) GetBalance(r *http.Request, args *AccountArgs, result *GetBalanceResult) error {
+	log.Println("'GetBalance' Called")
+
+	account, height, err := api.account(args)
+	if err != nil {
+		return err
+	}
+
+	*result = GetBalanceResult{
+		Address:       args.Address,
+		Balance:       account.Balance,
+		Spendable:     account.Spendable(height + 1),
+		Denominations: denominations(account.Balance),
+		BlockHeight:   uint64(height),
+	}
+
+	if args.Pending {
+		pending, err := api.pendingAccount(common.Address(args.Address))
+		if err != nil {
+			return err
+		}
+
+		result.PendingBalance = &pending.Balance
+	}
+
+	return nil
+}
+
+type GetNonceResult struct {
+	Address      string  `json:"address"`
+	Nonce        uint64  `json:"nonce"`
+	PendingNonce *uint64 `json:"pending_nonce,omitempty"`
+	BlockHeight  uint64  `json:"block_height"`
+}
+
+func (api *API) GetNonce(r *http.Request, args *AccountArgs, result *GetNonceResult) error {
+	log.Println("'GetNonce' Called")
+
+	account, height, err := api.account(args)
+	if err != nil {
+		return err
+	}
+
+	*result = GetNonceResult{
+		Address:     args.Address,
+		Nonce:       account.Nonce,
+		BlockHeight: uint64(height),
+	}
+
+	if args.Pending {
+		pending, err := api.pendingAccount(common.Address(args.Address))
+		if err != nil {
+			return err
+		}
+
+		result.PendingNonce = &pending.Nonce
+	}
+
+	return nil
+}
+
+type GetAccountResult struct {
+	Address        string            `json:"address"`
+	Balance        uint64            `json:"balance"`
+	Spendable      uint64            `json:"spendable"`
+	Denominations  map[string]string `json:"denominations"`
+	Nonce          uint64            `json:"nonce"`
+	Immature       []Credit          `json:"immature,omitempty"`
+	Stake          uint64            `json:"stake,omitempty"`
+	Unbonding      []Credit          `json:"unbonding,omitempty"`
+	PendingBalance *uint64           `json:"pending_balance,omitempty"`
+	PendingNonce   *uint64           `json:"pending_nonce,omitempty"`
+	BlockHeight    uint64            `json:"block_height"`
+}
+
+func (api *API) GetAccount(r *http.Request, args *AccountArgs, result *GetAccountResult) error {
+	log.Println("'GetAccount' Called")
+
+	account, height, err := api.account(args)
+	if err != nil {
+		return err
+	}
+
+	*result = GetAccountResult{
+		Address:       args.Address,
+		Balance:       account.Balance,
+		Spendable:     account.Spendable(height + 1),
+		Denominations: denominations(account.Balance),
+		Nonce:         account.Nonce,
+		Immature:      credits(account),
+		Stake:         account.Stake,
+		Unbonding:     unbonding(account),
+		BlockHeight:   uint64(height),
+	}
+
+	if args.Pending {
+		pending, err := api.pendingAccount(common.Address(args.Address))
+		if err != nil {
+			return err
+		}
+
+		result.PendingBalance, result.PendingNonce = &pending.Balance, &pending.Nonce
+	}
+
+	return nil
+}
+
+// denominations returns the given amount of Nubs formatted in each denomination
+func denominations(amount uint64) map[string]string {
+	formatted := make(map[string]string, len(common.Denominations))
+	for _, denomination := range common.Denominations {
+		formatted[denomination.Name] = common.FormatAmount(amount, denomination.Nubs)
+	}
+
+	return formatted
+}
+
+// pendingAccount returns the Account of the given address after the transactions in the pool are
+// applied to the state at the chain head. Transactions that fail the state transition are skipped.
+func (api *API) pendingAccount(address common.Address) (*state.Account, error) {
+	accounts := api.chain.State()
+	for _, txn := range api.pool.Transactions() {
+		_ = accounts.ApplyTransaction(txn)
+	}
+
+	account, err := accounts.GetAccount(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending account: %w", err)
+	}
+
+	return account, nil
+}
+
+// Credit is an immature coinbase credit or unbonding stake of an account that is locked until its release height
+type Credit struct {
+	Amount  uint64 `json:"amount"`
+	Release int64  `json:"release"`
+}
+
+// credits returns the immature coinbase credits of the given Account
+func credits(account *state.Account) []Credit {
+	if len(account.Immature) == 0 {
+		return nil
+	}
+
+	result := make([]Credit, 0, len(account.Immature))
+	for _, credit := range account.Immature {
+		result = append(result, Credit{credit.Amount, credit.Release})
+	}
+
+	return result
+}
+
+// unbonding returns the unbonding stake of the given Account
+func unbonding(account *state.Account) []Credit {
+	if len(account.Unbonding) == 0 {
+		return nil
+	}
+
+	result := make([]Credit, 0, len(account.Unbonding))
+	for _, unbond := range account.Unbonding {
+		result = append(result, Credit{unbond.Amount, unbond.Release})
+	}
+
+	return result
+}
+
+// account returns the Account for the given arguments and the height of the block it was read at
+func (api *API) account(args *AccountArgs) (*state.Account, int64, error) {
+	address := common.Address(args.Address)
+
+	// The pending state only follows the chain head
+	if args.Pending && (args.BlockHash != "" || args.Height != nil) {
+		return nil, 0, fmt.Errorf("pending state is only available at the chain head")
+	}
+
+	var (
+		hash common.Hash
+		err  error
+	)
+
+	switch {
+	// Read the state at the block with the given hash
+	case args.BlockHash != "":
+		data, err := common.HexDecode(args.BlockHash)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid block hash: %w", err)
+		}
+
+		hash = common.BytesToHash(data)
+
+	// Read the state at the block with the given height
+	case args.Height != nil:
+		if hash, err = api.chain.GetHashByHeight(*args.Height); err != nil {
+			return nil, 0, fmt.Errorf("failed to get block: %w", err)
+		}
+
+	// Read the state at the chain head
+	default:
+		_, height := api.chain.CurrentHead()
+		head := height - 1
+		account, err := api.chain.GetAccount(address)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get account: %w", err)
+		}
+
+		return account, head, nil
+	}
+
+	block, err := api.chain.GetBlock(hash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get block: %w", err)
+	}
+
+	account, err := api.chain.GetAccountAt(address, hash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get account at block %v: %w", block.BlockHeight, err)
+	}
+
+	return account, block.BlockHeight, nil
+}
@@ -13,10 +13,15 @@ type AddBlockArgs struct {
 	Transactions []TransactionInput `json:"transactions"`
 }
 
+// TransactionInput is a transaction of AddBlock. The chain ID is required and must be the chain ID
+// of the node. The nonce defaults to the next nonce of the sender if it is not set.
 type TransactionInput struct {
-	To    string `json:"to"`
-	From  string `json:"from"`
-	Value uint64 `json:"value"`
+	ChainID *uint64 `json:"chain_id"`
+	To      string  `json:"to"`
+	From    string  `json:"from"`
+	Value   uint64  `json:"value"`
+	Fee     uint64  `json:"fee"`
+	Nonce   *uint64 `json:"nonce,omitempty"`
 }
 
 type AddBlockResult struct {
@@ -31,19 +36,50 @@ func (api *API) AddBlock(r *http.Request, args *AddBlockArgs, result *AddBlockRe
 		return fmt.Errorf("no transactions receieved")
 	}
 
+	// Check the transactions against the state at the chain head
+	accounts := api.chain.State()
+
 	transactions := make(core.Transactions, 0, len(args.Transactions))
-	for _, txn := range args.Transactions {
-		newtxn := core.NewTransaction(common.Address(txn.From), common.Address(txn.To), 0, txn.Value)
+	for index, txn := range args.Transactions {
+		from := common.Address(txn.From)
+
+		// Use the next nonce of the sender if the nonce is not provided
+		nonce, err := accounts.GetNonce(from)
+		if err != nil {
+			return fmt.Errorf("failed to get sender nonce: %w", err)
+		}
+
+		if txn.Nonce != nil {
+			nonce = *txn.Nonce
+		}
+
+		// Reject transactions for other networks
+		if txn.ChainID == nil {
+			return fmt.Errorf("invalid transaction %v: chain id is required", index)
+		}
+
+		if *txn.ChainID != api.chain.Config().ChainID {
+			return fmt.Errorf("invalid transaction %v: %w", index, core.ErrInvalidChainID)
+		}
+
+		newtxn := core.NewTransaction(*txn.ChainID, from, common.Address(txn.To), nonce, txn.Value, txn.Fee)
+		if err := accounts.ApplyTransaction(newtxn); err != nil {
+			return fmt.Errorf("invalid transaction %v: %w", index, err)
+		}
+
 		transactions = append(transactions, newtxn)
 	}
 
-	if err := api.chain.AddBlock(transactions); err != nil {
+	// Mine the block with the request context, so that it is
+	// cancelled if the client disconnects or the node shuts down
+	block, err := api.chain.AddBlock(r.Context(), transactions)
+	if err != nil {
 		return fmt.Errorf("failed to add block: %w", err)
 	}
 
 	*result = AddBlockResult{
-		BlockHeight: uint64(api.chain.Height - 1),
-		BlockHash:   api.chain.Head.Hex(),
+		BlockHeight: uint64(block.BlockHeight),
+		BlockHash:   block.BlockHash.Hex(),
 	}
 
 	return nil
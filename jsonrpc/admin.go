@@ -0,0 +1,125 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type StartMinerArgs struct {
+	Threads int `json:"threads"`
+}
+
+type MinerStatusResult struct {
+	Running bool `json:"running"`
+	Threads int  `json:"threads"`
+}
+
+func (api *API) StartMiner(r *http.Request, args *StartMinerArgs, result *MinerStatusResult) error {
+	log.Println("'StartMiner' Called")
+
+	if err := api.requireAdmin(r); err != nil {
+		return err
+	}
+
+	// Update the thread count if provided
+	if args.Threads > 0 {
+		api.miner.SetThreads(args.Threads)
+	}
+
+	if !api.miner.Start() {
+		return fmt.Errorf("miner is already running")
+	}
+
+	*result = api.minerStatus()
+	return nil
+}
+
+type StopMinerArgs struct{}
+
+func (api *API) StopMiner(r *http.Request, args *StopMinerArgs, result *MinerStatusResult) error {
+	log.Println("'StopMiner' Called")
+
+	if err := api.requireAdmin(r); err != nil {
+		return err
+	}
+
+	if !api.miner.Stop() {
+		return fmt.Errorf("miner is not running")
+	}
+
+	*result = api.minerStatus()
+	return nil
+}
+
+type SetMinerThreadsArgs struct {
+	Threads int `json:"threads"`
+}
+
+func (api *API) SetMinerThreads(r *http.Request, args *SetMinerThreadsArgs, result *MinerStatusResult) error {
+	log.Println("'SetMinerThreads' Called")
+
+	if err := api.requireAdmin(r); err != nil {
+		return err
+	}
+
+	if args.Threads < 1 {
+		return fmt.Errorf("thread count must be at least 1")
+	}
+
+	api.miner.SetThreads(args.Threads)
+
+	*result = api.minerStatus()
+	return nil
+}
+
+type MinerStatusArgs struct{}
+
+func (api *API) MinerStatus(r *http.Request, args *MinerStatusArgs, result *MinerStatusResult) error {
+	log.Println("'MinerStatus' Called")
+
+	*result = api.minerStatus()
+	return nil
+}
+
+type SetHeadArgs struct {
+	Height int64 `json:"height"`
+}
+
+type SetHeadResult struct {
+	ChainHead   string `json:"chain_head"`
+	ChainHeight uint64 `json:"chain_height"`
+}
+
+func (api *API) SetHead(r *http.Request, args *SetHeadArgs, result *SetHeadResult) error {
+	log.Println("'SetHead' Called")
+
+	if err := api.requireAdmin(r); err != nil {
+		return err
+	}
+
+	if err := api.chain.SetHead(args.Height); err != nil {
+		return fmt.Errorf("failed to set head: %w", err)
+	}
+
+	// Drop the jobs built on the rewound blocks
+	api.jobsMu.Lock()
+	api.dropStaleJobs()
+	api.jobsMu.Unlock()
+
+	head, height := api.chain.CurrentHead()
+	*result = SetHeadResult{
+		ChainHead:   head.Hex(),
+		ChainHeight: uint64(height),
+	}
+
+	return nil
+}
+
+// minerStatus returns the running state and thread count of the background miner
+func (api *API) minerStatus() MinerStatusResult {
+	return MinerStatusResult{
+		Running: api.miner.Running(),
+		Threads: api.miner.Threads(),
+	}
+}
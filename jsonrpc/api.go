@@ -1,24 +1,83 @@
 package jsonrpc
 
 import (
-	"log"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
 
 	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/core/txpool"
+	"github.com/manishmeganathan/essensio/miner"
+)
+
+var (
+	// ErrAdminDisabled is returned when an admin method is called without the admin methods enabled
+	ErrAdminDisabled = errors.New("admin methods are disabled")
+	// ErrAdminRemote is returned when an admin method is called by a client that is not on the loopback interface
+	ErrAdminRemote = errors.New("admin methods are only available to local clients")
 )
 
 type API struct {
 	chain *chainmgr.ChainManager
+	pool  *txpool.TxnNoncePool
+
+	// admin is whether the admin methods are enabled, which
+	// control the miner and the chain head of the node
+	admin bool
+
+	// miner mines blocks from the pool in the background and
+	// builder assembles the block templates from the pool
+	miner   *miner.Miner
+	builder *miner.Builder
+
+	// jobs are the block templates handed out to external
+	// miners with GetWork, indexed by their job id
+	jobsMu sync.Mutex
+	jobs   map[string]*workJob
+
+	// quit is closed when the API is stopped
+	quit chan struct{}
 }
 
-func NewAPI() *API {
-	chain, err := chainmgr.NewChainManager()
-	if err != nil {
-		log.Fatalln("Failed to Start Blockchain:", err)
+func NewAPI(chain *chainmgr.ChainManager, pool *txpool.TxnNoncePool, miner *miner.Miner, admin bool) *API {
+	api := &API{
+		chain:   chain,
+		pool:    pool,
+		admin:   admin,
+		miner:   miner,
+		builder: miner.Builder(),
+		jobs:    make(map[string]*workJob),
+		quit:    make(chan struct{}),
 	}
 
-	return &API{chain}
+	go api.expireJobs()
+	return api
 }
 
 func (api *API) Stop() {
+	close(api.quit)
+
+	// Stop the background miner before the chain
+	api.miner.Stop()
 	api.chain.Stop()
 }
+
+// requireAdmin checks that the admin methods are enabled and that the
+// given request was made by a client on the loopback interface
+func (api *API) requireAdmin(r *http.Request) error {
+	if !api.admin {
+		return ErrAdminDisabled
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return ErrAdminRemote
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return ErrAdminRemote
+	}
+
+	return nil
+}
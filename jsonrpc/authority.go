@@ -0,0 +1,111 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus/poa"
+)
+
+type ProposeSignerArgs struct {
+	Address   string `json:"address"`
+	Authorize bool   `json:"authorize"`
+}
+
+type ProposeSignerResult struct{}
+
+func (api *API) ProposeSigner(r *http.Request, args *ProposeSignerArgs, result *ProposeSignerResult) error {
+	log.Println("'ProposeSigner' Called")
+
+	if err := api.requireAdmin(r); err != nil {
+		return err
+	}
+
+	address, err := common.HexToAddress(args.Address)
+	if err != nil {
+		return err
+	}
+
+	engine, err := api.authority()
+	if err != nil {
+		return err
+	}
+
+	engine.Propose(address, args.Authorize)
+	return nil
+}
+
+type DiscardProposalArgs struct {
+	Address string `json:"address"`
+}
+
+type DiscardProposalResult struct{}
+
+func (api *API) DiscardProposal(r *http.Request, args *DiscardProposalArgs, result *DiscardProposalResult) error {
+	log.Println("'DiscardProposal' Called")
+
+	if err := api.requireAdmin(r); err != nil {
+		return err
+	}
+
+	address, err := common.HexToAddress(args.Address)
+	if err != nil {
+		return err
+	}
+
+	engine, err := api.authority()
+	if err != nil {
+		return err
+	}
+
+	return engine.Discard(address)
+}
+
+type GetSignersArgs struct{}
+
+type GetSignersResult struct {
+	Signers   []string        `json:"signers"`
+	Proposals map[string]bool `json:"proposals"`
+}
+
+func (api *API) GetSigners(r *http.Request, args *GetSignersArgs, result *GetSignersResult) error {
+	log.Println("'GetSigners' Called")
+
+	engine, err := api.authority()
+	if err != nil {
+		return err
+	}
+
+	// Get the snapshot of the authorities at the chain head
+	_, height := api.chain.CurrentHead()
+	snap, err := engine.Snapshot(api.chain, height-1)
+	if err != nil {
+		return fmt.Errorf("failed to get signers: %w", err)
+	}
+
+	signers := make([]string, 0, len(snap.Signers))
+	for _, signer := range snap.SignerList() {
+		signers = append(signers, string(signer))
+	}
+
+	proposals := make(map[string]bool)
+	for address, authorize := range engine.Proposals() {
+		proposals[string(address)] = authorize
+	}
+
+	*result = GetSignersResult{signers, proposals}
+	return nil
+}
+
+// authority returns the consensus engine of the chain as a
+// Proof of Authority engine or an error if it is not one
+func (api *API) authority() (*poa.PoA, error) {
+	engine, ok := api.chain.Engine().(*poa.PoA)
+	if !ok {
+		return nil, fmt.Errorf("chain is not running proof of authority")
+	}
+
+	return engine, nil
+}
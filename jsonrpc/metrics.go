@@ -0,0 +1,24 @@
+package jsonrpc
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+)
+
+type GetMetricsArgs struct{}
+
+type GetMetricsResult struct {
+	Caches map[string]chainmgr.CacheMetrics `json:"caches"`
+}
+
+func (api *API) GetMetrics(r *http.Request, args *GetMetricsArgs, result *GetMetricsResult) error {
+	log.Println("'GetMetrics' Called")
+
+	*result = GetMetricsResult{
+		Caches: api.chain.CacheMetrics(),
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type MineBlockArgs struct{}
+
+type MineBlockResult struct {
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	TxnCount    int    `json:"txn_count"`
+}
+
+func (api *API) MineBlock(r *http.Request, args *MineBlockArgs, result *MineBlockResult) error {
+	log.Println("'MineBlock' Called")
+
+	// Mine a block from the transaction pool with the request context,
+	// so that it is cancelled if the client disconnects or the node shuts down
+	block, err := api.builder.Mine(r.Context())
+	if err != nil {
+		return fmt.Errorf("failed to mine block: %w", err)
+	}
+
+	*result = MineBlockResult{
+		BlockHeight: uint64(block.BlockHeight),
+		BlockHash:   block.BlockHash.Hex(),
+		TxnCount:    block.TxnCount(),
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+)
+
+type GetProofArgs struct {
+	Address string `json:"address"`
+	Height  *int64 `json:"height,omitempty"`
+}
+
+type GetProofResult struct {
+	Address     string     `json:"address"`
+	Balance     uint64     `json:"balance"`
+	Nonce       uint64     `json:"nonce"`
+	Immature    []Credit   `json:"immature,omitempty"`
+	Stake       uint64     `json:"stake,omitempty"`
+	Unbonding   []Credit   `json:"unbonding,omitempty"`
+	BlockHeight uint64     `json:"block_height"`
+	BlockHash   string     `json:"block_hash"`
+	StateRoot   string     `json:"state_root"`
+	Siblings    []string   `json:"siblings"`
+	Leaf        *ProofLeaf `json:"leaf"`
+}
+
+type ProofLeaf struct {
+	Key       string   `json:"key"`
+	Balance   uint64   `json:"balance"`
+	Nonce     uint64   `json:"nonce"`
+	Immature  []Credit `json:"immature,omitempty"`
+	Stake     uint64   `json:"stake,omitempty"`
+	Unbonding []Credit `json:"unbonding,omitempty"`
+}
+
+func (api *API) GetProof(r *http.Request, args *GetProofArgs, result *GetProofResult) error {
+	log.Println("'GetProof' Called")
+
+	// Default to the chain head
+	_, chainHeight := api.chain.CurrentHead()
+	height := chainHeight - 1
+	if args.Height != nil {
+		height = *args.Height
+	}
+
+	hash, err := api.chain.GetHashByHeight(height)
+	if err != nil {
+		return fmt.Errorf("failed to get block: %w", err)
+	}
+
+	header, err := api.chain.GetHeader(hash)
+	if err != nil {
+		return fmt.Errorf("failed to get block: %w", err)
+	}
+
+	account, proof, err := api.chain.ProveAccount(common.Address(args.Address), hash)
+	if err != nil {
+		return fmt.Errorf("failed to get proof: %w", err)
+	}
+
+	siblings := make([]string, 0, len(proof.Siblings))
+	for _, sibling := range proof.Siblings {
+		siblings = append(siblings, sibling.Hex())
+	}
+
+	*result = GetProofResult{
+		Address:     args.Address,
+		Balance:     account.Balance,
+		Nonce:       account.Nonce,
+		Immature:    credits(account),
+		Stake:       account.Stake,
+		Unbonding:   unbonding(account),
+		BlockHeight: uint64(height),
+		BlockHash:   hash.Hex(),
+		StateRoot:   header.StateRoot.Hex(),
+		Siblings:    siblings,
+	}
+
+	if proof.Leaf != nil {
+		leaf := proof.Leaf.Account
+		result.Leaf = &ProofLeaf{proof.Leaf.Key.Hex(), leaf.Balance, leaf.Nonce, credits(&leaf), leaf.Stake, unbonding(&leaf)}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/state"
+)
+
+// SendTransactionArgs are the arguments of SendTransaction.
+// The chain ID is required and must be the chain ID of the node.
+type SendTransactionArgs struct {
+	ChainID *uint64 `json:"chain_id"`
+	To      string  `json:"to"`
+	From    string  `json:"from"`
+	Value   uint64  `json:"value"`
+	Fee     uint64  `json:"fee"`
+	Nonce   uint64  `json:"nonce"`
+}
+
+type SendTransactionResult struct {
+	TxnHash string `json:"txn_hash"`
+}
+
+func (api *API) SendTransaction(r *http.Request, args *SendTransactionArgs, result *SendTransactionResult) error {
+	log.Println("'SendTransaction' Called")
+
+	if args.From == "" || args.To == "" {
+		return fmt.Errorf("transaction sender and receiver are required")
+	}
+
+	// Reject transactions without a chain ID, the signer must commit to a network
+	if args.ChainID == nil {
+		return fmt.Errorf("transaction chain id is required")
+	}
+
+	txn := core.NewTransaction(*args.ChainID, common.Address(args.From), common.Address(args.To), args.Nonce, args.Value, args.Fee)
+
+	// Check the transaction against the state at the chain head
+	accounts := api.chain.State()
+
+	// Reject transactions with a nonce that has already been used
+	nonce, err := accounts.GetNonce(txn.From)
+	if err != nil {
+		return fmt.Errorf("failed to get sender nonce: %w", err)
+	}
+
+	if txn.Nonce < nonce {
+		return fmt.Errorf("invalid transaction: %w", state.ErrNonceTooLow)
+	}
+
+	// Reject transactions that the sender cannot pay with its spendable balance
+	if err := accounts.CheckBalance(txn); err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	// Add the transaction to the pool to be included in a mined block
+	// Transactions for other networks are rejected by the pool
+	if err := api.pool.Insert(txn); err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	*result = SendTransactionResult{
+		TxnHash: txn.Hash().Hex(),
+	}
+
+	return nil
+}
@@ -27,18 +27,21 @@ type ChainBlock struct {
 }
 
 type BlockTransaction struct {
-	To    string `json:"to"`
-	From  string `json:"from"`
-	Value uint64 `json:"value"`
-	Nonce uint64 `json:"nonce"`
+	ChainID uint64 `json:"chain_id"`
+	To      string `json:"to"`
+	From    string `json:"from"`
+	Value   uint64 `json:"value"`
+	Fee     uint64 `json:"fee"`
+	Nonce   uint64 `json:"nonce"`
 }
 
 func (api *API) ShowChain(r *http.Request, args *ShowChainArgs, result *ShowChainResult) error {
 	log.Println("'ShowChain' Called")
 
+	head, height := api.chain.CurrentHead()
 	chainresult := ShowChainResult{
-		ChainHead:   api.chain.Head.Hex(),
-		ChainHeight: uint64(api.chain.Height),
+		ChainHead:   head.Hex(),
+		ChainHeight: uint64(height),
 	}
 
 	iterator := api.chain.NewIterator()
@@ -52,8 +55,8 @@ func (api *API) ShowChain(r *http.Request, args *ShowChainArgs, result *ShowChai
 		transactions := make([]BlockTransaction, 0, block.TxnCount())
 		for _, txn := range block.BlockTxns {
 			transactions = append(transactions, BlockTransaction{
-				string(txn.To), string(txn.From),
-				txn.Value, txn.Nonce,
+				txn.ChainID, string(txn.To), string(txn.From),
+				txn.Value, txn.Fee, txn.Nonce,
 			})
 		}
 
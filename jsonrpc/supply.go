@@ -0,0 +1,74 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/core/state"
+)
+
+// GetSupplyArgs are the arguments of GetSupply. The supply is
+// reported after the block at the given height or at the chain head.
+type GetSupplyArgs struct {
+	Height *int64 `json:"height,omitempty"`
+}
+
+type GetSupplyResult struct {
+	BlockHeight     uint64 `json:"block_height"`
+	BlockReward     uint64 `json:"block_reward"`
+	NextBlockReward uint64 `json:"next_block_reward"`
+	Allocated       uint64 `json:"allocated"`
+	Emitted         uint64 `json:"emitted"`
+	TotalSupply     uint64 `json:"total_supply"`
+	MaxSupply       uint64 `json:"max_supply"`
+}
+
+func (api *API) GetSupply(r *http.Request, args *GetSupplyArgs, result *GetSupplyResult) error {
+	log.Println("'GetSupply' Called")
+
+	config := api.chain.Config()
+
+	_, chainHeight := api.chain.CurrentHead()
+	height := chainHeight - 1
+	if args.Height != nil {
+		if *args.Height < 0 || *args.Height > height {
+			return fmt.Errorf("invalid block height: %v", *args.Height)
+		}
+
+		height = *args.Height
+	}
+
+	// Sum the allocations minted in the genesis block, after its coinbase
+	genesis, err := api.chain.GetBlockByHeight(0)
+	if err != nil {
+		return fmt.Errorf("failed to get genesis block: %w", err)
+	}
+
+	var allocated uint64
+	for _, txn := range genesis.BlockTxns[1:] {
+		if state.IsMint(txn) {
+			allocated += txn.Value
+		}
+	}
+
+	emitted := config.Emission(height + 1)
+
+	// The maximum supply is only reported if the block rewards are capped
+	var maximum uint64
+	if config.MaxSupply > 0 {
+		maximum = allocated + config.MaxSupply
+	}
+
+	*result = GetSupplyResult{
+		BlockHeight:     uint64(height),
+		BlockReward:     config.BlockRewardAt(height),
+		NextBlockReward: config.BlockRewardAt(height + 1),
+		Allocated:       allocated,
+		Emitted:         emitted,
+		TotalSupply:     allocated + emitted,
+		MaxSupply:       maximum,
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/manishmeganathan/essensio/consensus/pos"
+)
+
+type GetValidatorsArgs struct{}
+
+type GetValidatorsResult struct {
+	Stakes    map[string]uint64 `json:"stakes"`
+	Unbonding []UnbondingStake  `json:"unbonding"`
+	Proposer  string            `json:"next_proposer"`
+}
+
+type UnbondingStake struct {
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+	Release int64  `json:"release_height"`
+}
+
+func (api *API) GetValidators(r *http.Request, args *GetValidatorsArgs, result *GetValidatorsResult) error {
+	log.Println("'GetValidators' Called")
+
+	engine, ok := api.chain.Engine().(*pos.PoS)
+	if !ok {
+		return fmt.Errorf("chain is not running proof of stake")
+	}
+
+	// Get the registry of the validators at the chain head
+	_, height := api.chain.CurrentHead()
+	registry, err := engine.Registry(api.chain, height-1)
+	if err != nil {
+		return fmt.Errorf("failed to get validators: %w", err)
+	}
+
+	proposer, err := engine.Proposer(api.chain, height)
+	if err != nil {
+		return fmt.Errorf("failed to get next proposer: %w", err)
+	}
+
+	stakes := make(map[string]uint64, len(registry.Stakes))
+	for address, stake := range registry.Stakes {
+		stakes[string(address)] = stake
+	}
+
+	unbonding := make([]UnbondingStake, 0, len(registry.Unbonding))
+	for _, unbond := range registry.Unbonding {
+		unbonding = append(unbonding, UnbondingStake{string(unbond.Address), unbond.Amount, unbond.Release})
+	}
+
+	*result = GetValidatorsResult{stakes, unbonding, string(proposer)}
+	return nil
+}
@@ -0,0 +1,201 @@
+package jsonrpc
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus/pow"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+)
+
+const (
+	// maxWorkJobs is the maximum number of GetWork jobs that are kept, the oldest job is dropped beyond it
+	maxWorkJobs = 16
+	// workJobTTL is the duration after which an unsubmitted GetWork job is dropped
+	workJobTTL = 2 * time.Minute
+)
+
+// workJob is a block template handed out to an external miner with GetWork
+type workJob struct {
+	block   *core.Block
+	created time.Time
+}
+
+type GetWorkArgs struct{}
+
+type GetWorkResult struct {
+	JobID       string `json:"job_id"`
+	BlockHeight uint64 `json:"block_height"`
+	Algorithm   string `json:"algorithm"`
+	Header      string `json:"header"`
+	NonceOffset int    `json:"nonce_offset"`
+	Target      string `json:"target"`
+	TxnCount    int    `json:"txn_count"`
+}
+
+func (api *API) GetWork(r *http.Request, args *GetWorkArgs, result *GetWorkResult) error {
+	log.Println("'GetWork' Called")
+
+	if _, ok := api.chain.Engine().(*pow.PoW); !ok {
+		return fmt.Errorf("chain is not running proof of work")
+	}
+
+	api.jobsMu.Lock()
+	defer api.jobsMu.Unlock()
+
+	// Drop any jobs that no longer extend the chain head or have expired
+	api.dropStaleJobs()
+
+	// Drop the oldest job to make room for the new job
+	if len(api.jobs) >= maxWorkJobs {
+		api.dropOldestJob()
+	}
+
+	// Build a block template from the executable transactions of the pool
+	block, err := api.builder.Build()
+	if err != nil {
+		return fmt.Errorf("failed to build block template: %w", err)
+	}
+
+	// A random id identifies the job, since templates built in the same second can be identical
+	jobID, err := newJobID()
+	if err != nil {
+		api.builder.Discard(block)
+		return err
+	}
+
+	api.jobs[jobID] = &workJob{block, time.Now()}
+
+	algorithm := api.chain.Config().PoWAlgorithm
+	if algorithm == "" {
+		algorithm = core.PoWSHA256d
+	}
+
+	*result = GetWorkResult{
+		JobID:       jobID,
+		BlockHeight: uint64(block.BlockHeight),
+		Algorithm:   algorithm,
+		Header:      common.HexEncode(block.WorkData()),
+		NonceOffset: core.HeaderNonceOffset,
+		Target:      common.BytesToHash(block.Target.Bytes()).Hex(),
+		TxnCount:    block.TxnCount(),
+	}
+
+	return nil
+}
+
+type SubmitWorkArgs struct {
+	JobID string `json:"job_id"`
+	Nonce int64  `json:"nonce"`
+}
+
+type SubmitWorkResult struct {
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+}
+
+func (api *API) SubmitWork(r *http.Request, args *SubmitWorkArgs, result *SubmitWorkResult) error {
+	log.Println("'SubmitWork' Called")
+
+	api.jobsMu.Lock()
+	defer api.jobsMu.Unlock()
+
+	// Drop any expired jobs before looking up the submitted job
+	api.dropStaleJobs()
+
+	job, ok := api.jobs[args.JobID]
+	if !ok {
+		return fmt.Errorf("unknown or expired job '%v'", args.JobID)
+	}
+
+	// Seal the block template with the submitted nonce
+	sealed := *job.block
+	sealed.Nonce = args.Nonce
+	sealed.BlockHash = sealed.Hash()
+
+	// Insert the block, the job is kept for other submissions if the nonce is invalid
+	if err := api.chain.InsertBlock(&sealed); err != nil {
+		if errors.Is(err, chainmgr.ErrUnknownPriori) {
+			api.dropStaleJobs()
+		}
+
+		return fmt.Errorf("failed to submit work: %w", err)
+	}
+
+	// Remove the mined transactions from the pool
+	delete(api.jobs, args.JobID)
+	api.builder.Commit(&sealed)
+
+	// All other jobs were built on the previous head
+	api.dropStaleJobs()
+
+	*result = SubmitWorkResult{
+		BlockHeight: uint64(sealed.BlockHeight),
+		BlockHash:   sealed.BlockHash.Hex(),
+	}
+
+	return nil
+}
+
+// newJobID returns a new random GetWork job id
+func newJobID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+
+	return common.HexEncode(id[:]), nil
+}
+
+// dropStaleJobs removes the jobs that do not extend the chain head or are older than workJobTTL
+// and restores their transactions into the pool. Must be called with the jobs mutex held.
+func (api *API) dropStaleJobs() {
+	head, _ := api.chain.CurrentHead()
+
+	for id, job := range api.jobs {
+		if job.block.Priori != head || time.Since(job.created) > workJobTTL {
+			api.builder.Discard(job.block)
+			delete(api.jobs, id)
+		}
+	}
+}
+
+// dropOldestJob removes the oldest job and restores its transactions
+// into the pool. Must be called with the jobs mutex held.
+func (api *API) dropOldestJob() {
+	var oldest string
+	for id, job := range api.jobs {
+		if oldest == "" || job.created.Before(api.jobs[oldest].created) {
+			oldest = id
+		}
+	}
+
+	if job, ok := api.jobs[oldest]; ok {
+		api.builder.Discard(job.block)
+		delete(api.jobs, oldest)
+	}
+}
+
+// expireJobs periodically drops the expired jobs until the API is stopped,
+// so that their transactions are restored even if no more work is requested
+func (api *API) expireJobs() {
+	ticker := time.NewTicker(workJobTTL / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-api.quit:
+			return
+		case <-ticker.C:
+			api.jobsMu.Lock()
+			api.dropStaleJobs()
+			api.jobsMu.Unlock()
+		}
+	}
+}
@@ -1,15 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/rpc"
 	"github.com/gorilla/rpc/json"
 
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/core/txpool"
+	"github.com/manishmeganathan/essensio/crypto"
+	"github.com/manishmeganathan/essensio/db"
 	"github.com/manishmeganathan/essensio/jsonrpc"
+	"github.com/manishmeganathan/essensio/miner"
 )
 
 // TODO:
@@ -18,17 +33,210 @@ import (
 // 3. Tx Pool
 // 4. Update the RPC
 
-const SERVER_PORT = 8080
-
 func main() {
+	// Parse the command line flags
+	threads := flag.Int("threads", 1, "number of threads used to mine blocks")
+	mine := flag.Bool("mine", false, "start mining blocks from the transaction pool in the background")
+	engine := flag.String("consensus", core.EngineProofOfWork, "consensus engine used to seal blocks (pow, poa, pos)")
+	algorithm := flag.String("pow.algorithm", core.PoWSHA256d, "hashing algorithm used by proof of work (sha256d, scrypt)")
+	signer := flag.String("signer", "", "hex encoded private key seed used to sign blocks")
+	authorities := flag.String("authorities", "", "comma separated initial signer addresses for proof of authority")
+	validators := flag.String("validators", "", "comma separated initial validator addresses for proof of stake")
+	period := flag.Int64("period", 5, "minimum number of seconds between proof of authority or proof of stake blocks")
+	stateMode := flag.String("state.mode", "archive", "state storage mode (archive retains every state, pruned retains recent states)")
+	stateRetain := flag.Int64("state.retain", 128, "number of recent states retained in pruned mode")
+	dev := flag.Bool("dev", false, "run a development chain on the devnet network with an in-memory database and prefunded accounts")
+	devPeriod := flag.Int64("dev.period", 0, "number of seconds between development blocks (0 seals immediately)")
+	devAccounts := flag.Int("dev.accounts", 10, "number of prefunded development accounts")
+	genesisFile := flag.String("genesis", "", "path of a genesis JSON file with the chain parameters and initial allocations")
+	networkName := flag.String("network", core.NetworkMainnet, fmt.Sprintf("name of the network to join (%v)", strings.Join(core.NetworkNames(), ", ")))
+	dataRoot := flag.String("datadir", db.DefaultRoot(), "root directory under which the data of each network is stored")
+	rpcPort := flag.Int("rpc.port", 0, "port of the JSON-RPC server (defaults to the port of the network)")
+	rpcAdmin := flag.Bool("rpc.admin", false, "enable the admin methods that control the miner, chain head and signer votes for local clients")
+	flag.Parse()
+
+	// Collect the flags that are set on the command line
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	// Check the state mode, pruned mode retains the given number of recent states
+	var retain int64
+	switch *stateMode {
+	case "archive":
+	case "pruned":
+		if *stateRetain < 1 {
+			log.Fatalln("Pruned mode requires retaining at least 1 state")
+		}
+
+		retain = *stateRetain
+	default:
+		log.Fatalln("Unknown State Mode:", *stateMode)
+	}
+
+	// A development chain always runs on the devnet network with its own genesis and engine
+	if *dev {
+		if set["network"] && *networkName != core.NetworkDevnet {
+			log.Fatalln("Invalid Network: development chains only run on", core.NetworkDevnet)
+		}
+
+		for _, name := range []string{"genesis", "consensus", "pow.algorithm"} {
+			if set[name] {
+				log.Fatalf("Invalid Flags: -%v cannot be used with -dev\n", name)
+			}
+		}
+
+		*networkName = core.NetworkDevnet
+	}
+
+	// Select the network, which provides the default genesis and RPC port
+	network, err := core.GetNetwork(*networkName)
+	if err != nil {
+		log.Fatalln("Invalid Network:", err)
+	}
+
+	if *rpcPort == 0 {
+		*rpcPort = network.RPCPort
+	}
+
+	// Set up the genesis and chain configuration. A development chain or a genesis file
+	// sets the consensus engine instead of the network genesis and the command line flags.
+	var devKeys []crypto.PrivateKey
+
+	genesis := network.Genesis()
+	switch {
+	case *dev:
+		genesis, devKeys = devGenesis(network, *devAccounts, *devPeriod)
+	case *genesisFile != "":
+		if genesis, err = core.LoadGenesis(*genesisFile); err != nil {
+			log.Fatalln("Failed to Load Genesis:", err)
+		}
+	}
+
+	config := genesis.Config
+	if !*dev && *genesisFile == "" {
+		if set["consensus"] {
+			config.Engine = *engine
+		}
+
+		// The scrypt algorithm starts at a lower difficulty
+		if set["pow.algorithm"] {
+			config.PoWAlgorithm = *algorithm
+			if config.PoWAlgorithm == core.PoWScrypt {
+				config.GenesisDifficulty = core.ScryptBlockDifficulty
+				config.MinimumDifficulty = core.ScryptBlockDifficulty / 2
+			}
+		}
+	}
+
+	// Load the signer key if provided, the first development account receives the block rewards by default
+	var key crypto.PrivateKey
+	if *dev {
+		key = devKeys[0]
+	}
+
+	if *signer != "" {
+		if key, err = crypto.KeyFromHex(*signer); err != nil {
+			log.Fatalln("Invalid Signer Key:", err)
+		}
+	}
+
+	// Proof of Authority and Proof of Stake sign blocks with the signer key
+	switch {
+	case config.Engine == core.EngineProofOfAuthority && key == nil:
+		log.Fatalln("Proof of Authority requires a signer key")
+	case config.Engine == core.EngineProofOfStake && key == nil:
+		log.Fatalln("Proof of Stake requires a validator key")
+	}
+
+	// Configure the authorities from the flags unless set by the genesis file
+	if config.Engine == core.EngineProofOfAuthority && config.PoA == nil {
+		config.PoA = &core.PoAConfig{Period: *period, Epoch: 30000}
+
+		// Default to the signer as the only authority
+		if *authorities == "" {
+			config.PoA.Authorities = []common.Address{crypto.KeyToAddress(key)}
+		} else {
+			for _, authority := range strings.Split(*authorities, ",") {
+				config.PoA.Authorities = append(config.PoA.Authorities, common.Address(authority))
+			}
+		}
+	}
+
+	// Configure the validators from the flags unless set by the genesis file
+	if config.Engine == core.EngineProofOfStake && config.PoS == nil {
+		config.PoS = &core.PoSConfig{
+			Period:          *period,
+			UnbondingPeriod: 10,
+			MinimumStake:    common.Essence,
+			Validators:      make(map[common.Address]uint64),
+		}
+
+		// Default to the signer as the only validator, each initial validator has the same stake
+		if *validators == "" {
+			config.PoS.Validators[crypto.KeyToAddress(key)] = 1000 * common.Essence
+		} else {
+			for _, validator := range strings.Split(*validators, ",") {
+				config.PoS.Validators[common.Address(validator)] = 1000 * common.Essence
+			}
+		}
+	}
+
+	// Open the database, a development chain uses a throwaway in-memory database
+	var database *db.Database
+	if *dev {
+		database = db.OpenMemory()
+		fmt.Printf("Network: %v [Data: in-memory]\n", network.Name)
+	} else {
+		// A database directly in the data root predates named networks. It is not moved into the mainnet
+		// directory since its genesis block was generated before transactions had a chain ID.
+		if network.Name == core.NetworkMainnet && db.Exists(*dataRoot) {
+			log.Println("Ignoring Legacy Database:", *dataRoot, "predates named networks and is incompatible with the mainnet genesis")
+		}
+
+		// Each network is stored in its own directory under the data root
+		dir := db.Dir(*dataRoot, network.Name)
+		if database, err = db.Open(dir); err != nil {
+			log.Fatalln("Failed to Open Database:", err)
+		}
+
+		fmt.Printf("Network: %v [Data: %v]\n", network.Name, dir)
+	}
+
+	// Start the blockchain
+	chain, err := chainmgr.NewChainManager(genesis, database)
+	if err != nil {
+		log.Fatalln("Failed to Start Blockchain:", err)
+	}
+
+	// Prune old states unless running in archive mode
+	if retain > 0 {
+		if err := chain.SetStateRetention(retain); err != nil {
+			log.Fatalln("Failed to Prune State:", err)
+		}
+	}
+
+	// Set the signer key
+	if key != nil {
+		chain.Authorize(key)
+		fmt.Println("Coinbase Address:", crypto.KeyToAddress(key))
+	}
+
+	// Create the transaction pool and the background miner with the number of mining threads
+	pool := txpool.NewTxnNoncePool(config.ChainID)
+	blockMiner := miner.New(chain, pool)
+	blockMiner.SetThreads(*threads)
+
+	if *mine {
+		blockMiner.Start()
+	}
+
 	// Create a new RPC Server and register the JSON Codec
 	server := rpc.NewServer()
 	server.RegisterCodec(json.NewCodec(), "application/json")
 	server.RegisterCodec(json.NewCodec(), "application/json;charset=UTF-8")
 
 	// Create a new JSON-RPC API for Essensio
-	api := jsonrpc.NewAPI()
-	defer api.Stop()
+	api := jsonrpc.NewAPI(chain, pool, blockMiner, *rpcAdmin)
 
 	// Register the Essensio API with the Server
 	if err := server.RegisterService(api, ""); err != nil {
@@ -39,9 +247,40 @@ func main() {
 	router := mux.NewRouter()
 	router.Handle("/rpc", server)
 
+	// Set up the HTTP Server
+	httpServer := &http.Server{Addr: fmt.Sprintf(":%v", *rpcPort), Handler: router}
+
+	// Shutdown the node on an interrupt or terminate signal
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		fmt.Println("Server Stopping...")
+
+		// Stop the API first to stop the miner and cancel any in-flight mining
+		api.Stop()
+
+		// Wait for the in-flight requests to finish before closing the database
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Println("Server Shutdown Failed:", err)
+		}
+
+		chain.Close()
+	}()
+
 	// HTTP Listen & Serve
 	fmt.Println("Server Starting...")
-	if err := http.ListenAndServe(fmt.Sprintf(":%v", SERVER_PORT), router); err != nil {
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalln(err)
 	}
+
+	// Wait for the shutdown to complete
+	<-stopped
 }
@@ -0,0 +1,137 @@
+package miner
+
+import (
+	"context"
+	"errors"
+
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/core/state"
+	"github.com/manishmeganathan/essensio/core/txpool"
+)
+
+// MaxBlockTxns is the maximum number of pool Transactions included in a Block template.
+// This does not include the coinbase and other transactions added by the consensus engine.
+const MaxBlockTxns = 100
+
+// Builder assembles Block templates from the Transactions of a pool.
+// Transactions included in a template are pending in the pool until the template
+// is either committed to the chain with Commit or abandoned with Discard.
+type Builder struct {
+	chain *chainmgr.ChainManager
+	pool  *txpool.TxnNoncePool
+}
+
+// NewBuilder generates and returns a new Builder for the given chain and pool
+func NewBuilder(chain *chainmgr.ChainManager, pool *txpool.TxnNoncePool) *Builder {
+	return &Builder{chain, pool}
+}
+
+// Build generates a new unsealed Block template on top of the chain head.
+// Each sender's Transactions are collected in nonce order starting from its next nonce
+// in the account state, and a Transaction that fails the state transition ends the
+// collection for its sender. At most MaxBlockTxns Transactions are included in total.
+// The coinbase is prepended when the template is finalized by the consensus engine.
+func (builder *Builder) Build() (*core.Block, error) {
+	txns := builder.collect()
+
+	// Create the Block template with the collected transactions
+	block, err := builder.chain.NewBlockTemplate(txns)
+	if err != nil {
+		builder.pool.Restore(txns...)
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// Mine builds a Block template, seals it and inserts it into the chain.
+// The Transactions of the block are cleared from the pool if it is committed, otherwise they are restored.
+func (builder *Builder) Mine(ctx context.Context) (*core.Block, error) {
+	block, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	// Seal the Block template with the consensus engine
+	if err := builder.chain.SealBlock(ctx, block); err != nil {
+		builder.Discard(block)
+		return nil, err
+	}
+
+	// Insert the sealed Block into the chain
+	if err := builder.chain.InsertBlock(block); err != nil {
+		builder.Discard(block)
+		return nil, err
+	}
+
+	builder.Commit(block)
+	return block, nil
+}
+
+// Commit clears the Transactions of a Block template that has been committed to the chain from the pool
+func (builder *Builder) Commit(block *core.Block) {
+	builder.pool.Clear(block.BlockTxns...)
+}
+
+// Discard restores the Transactions of an abandoned Block template into the pool.
+// Transactions that were not collected from the pool, such as the coinbase, are ignored.
+func (builder *Builder) Discard(block *core.Block) {
+	builder.pool.Restore(block.BlockTxns...)
+}
+
+// collect fetches the executable Transactions for a Block template from the pool.
+// Transactions for other networks or with a nonce that has already been used are dropped
+// from the pool, all other Transactions that are not included are restored into the pool.
+func (builder *Builder) collect() core.Transactions {
+	txns := make(core.Transactions, 0, MaxBlockTxns)
+
+	// Check the transactions against the state at the chain head
+	accounts := builder.chain.State()
+
+	for _, sender := range builder.pool.Senders() {
+		// Stop once the block is full
+		if len(txns) == MaxBlockTxns {
+			break
+		}
+
+		// Fetch all the nonce sorted transactions of the sender
+		txset := builder.pool.FetchFor(sender)
+		if txset == nil {
+			continue
+		}
+
+		pending := txset.Flatten()
+
+		// Include the transactions that pass the state transition while the block has space
+		count := 0
+		for ; count < len(pending) && len(txns) < MaxBlockTxns; count++ {
+			// Drop transactions for other networks
+			if pending[count].ChainID != builder.chain.Config().ChainID {
+				builder.pool.Clear(pending[count])
+				continue
+			}
+
+			if err := accounts.ApplyTransaction(pending[count]); err != nil {
+				// Drop transactions that can never be included
+				if errors.Is(err, state.ErrNonceTooLow) || errors.Is(err, state.ErrUnauthorizedMint) || errors.Is(err, state.ErrInvalidRelease) {
+					builder.pool.Clear(pending[count])
+					continue
+				}
+
+				break
+			}
+
+			txns = append(txns, pending[count])
+		}
+
+		// Restore the transactions that were not included
+		builder.pool.Restore(pending[count:]...)
+	}
+
+	if len(txns) == 0 {
+		return nil
+	}
+
+	return txns
+}
@@ -0,0 +1,201 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/manishmeganathan/essensio/common"
+	"github.com/manishmeganathan/essensio/consensus/pos"
+	"github.com/manishmeganathan/essensio/core"
+	"github.com/manishmeganathan/essensio/core/chainmgr"
+	"github.com/manishmeganathan/essensio/core/txpool"
+)
+
+const (
+	// retryDelay is the time the Miner waits before building a new template after a failure
+	retryDelay = time.Second
+	// idleInterval is the interval at which an idle Miner checks the pool for transactions when the
+	// consensus engine seals blocks immediately, or checks the chain head when it is not the proposer
+	idleInterval = 100 * time.Millisecond
+)
+
+// Miner is a background service that continuously builds Block templates from the
+// transaction pool and seals them onto the chain. Work on a template is abandoned
+// and restarted on the new chain head whenever a block is inserted by someone else.
+type Miner struct {
+	chain   *chainmgr.ChainManager
+	pool    *txpool.TxnNoncePool
+	builder *Builder
+
+	// mu protects the running state and thread count of the Miner
+	mu sync.Mutex
+	// Represents the number of threads used to seal blocks
+	threads int
+	// Represents the cancel function and completion channel of the
+	// mining loop. They are nil when the Miner is not running.
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New generates and returns a new stopped Miner for the given chain and pool
+func New(chain *chainmgr.ChainManager, pool *txpool.TxnNoncePool) *Miner {
+	return &Miner{
+		chain:   chain,
+		pool:    pool,
+		builder: NewBuilder(chain, pool),
+		threads: 1,
+	}
+}
+
+// Builder returns the Block template Builder of the Miner
+func (miner *Miner) Builder() *Builder {
+	return miner.builder
+}
+
+// Start starts the mining loop in the background.
+// Returns false if the Miner is already running.
+func (miner *Miner) Start() bool {
+	// Acquire the mutex
+	miner.mu.Lock()
+	defer miner.mu.Unlock()
+
+	if miner.cancel != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	miner.cancel, miner.done = cancel, make(chan struct{})
+
+	go miner.loop(ctx, miner.done)
+
+	log.Printf("Miner Started with %v threads\n", miner.threads)
+	return true
+}
+
+// Stop stops the mining loop and waits for it to exit.
+// The Transactions of the abandoned template are restored into the pool.
+// Returns false if the Miner is not running.
+func (miner *Miner) Stop() bool {
+	// Acquire the mutex
+	miner.mu.Lock()
+	defer miner.mu.Unlock()
+
+	if miner.cancel == nil {
+		return false
+	}
+
+	// Cancel the mining loop and wait for it to exit
+	miner.cancel()
+	<-miner.done
+
+	miner.cancel, miner.done = nil, nil
+
+	log.Println("Miner Stopped")
+	return true
+}
+
+// Running returns whether the mining loop is running
+func (miner *Miner) Running() bool {
+	// Acquire the mutex
+	miner.mu.Lock()
+	defer miner.mu.Unlock()
+
+	return miner.cancel != nil
+}
+
+// Threads returns the number of threads used to seal blocks
+func (miner *Miner) Threads() int {
+	// Acquire the mutex
+	miner.mu.Lock()
+	defer miner.mu.Unlock()
+
+	return miner.threads
+}
+
+// SetThreads sets the number of threads used to seal blocks.
+// The new thread count applies from the next block template.
+func (miner *Miner) SetThreads(threads int) {
+	if threads < 1 {
+		threads = 1
+	}
+
+	// Acquire the mutex
+	miner.mu.Lock()
+	defer miner.mu.Unlock()
+
+	miner.threads = threads
+	miner.chain.SetMinerThreads(threads)
+}
+
+// loop mines blocks until the given context is cancelled and closes done when it exits
+func (miner *Miner) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for ctx.Err() == nil {
+		// Wait for transactions instead of sealing empty blocks without any delay
+		if miner.sealsInstantly() && miner.pool.Active() == 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(idleInterval):
+			}
+
+			continue
+		}
+
+		head, _ := miner.chain.CurrentHead()
+
+		block, err := miner.builder.Mine(ctx)
+		if err == nil {
+			log.Printf("Miner Sealed Block [%v]: %v\n", block.BlockHeight, block.BlockHash.Hex())
+			continue
+		}
+
+		// Restart on the new head if the chain head changed while mining
+		if errors.Is(err, core.ErrMiningCancelled) || errors.Is(err, chainmgr.ErrUnknownPriori) {
+			continue
+		}
+
+		// Wait for the block of the proposer if the validator is not the proposer
+		if errors.Is(err, pos.ErrNotProposer) {
+			miner.waitHead(ctx, head)
+			continue
+		}
+
+		// Stop if the chain has been stopped
+		if errors.Is(err, chainmgr.ErrChainStopped) {
+			return
+		}
+
+		log.Println("Miner Error:", err)
+
+		// Wait before retrying unless the miner is stopped
+		select {
+		case <-ctx.Done():
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// waitHead waits until the chain head is no longer the given head or the given context is cancelled
+func (miner *Miner) waitHead(ctx context.Context, head common.Hash) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(idleInterval):
+		}
+
+		if current, _ := miner.chain.CurrentHead(); current != head {
+			return
+		}
+	}
+}
+
+// sealsInstantly returns whether the consensus engine of the chain seals blocks without any work or delay
+func (miner *Miner) sealsInstantly() bool {
+	config := miner.chain.Config()
+	return config.Engine == core.EngineInstant && (config.Instant == nil || config.Instant.Period == 0)
+}